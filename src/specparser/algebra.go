@@ -0,0 +1,718 @@
+package specparser
+
+import "time"
+
+// Timetable is a set of fire times, a single TimeSpecExtended or a CompositeSchedule combining several
+type Timetable interface {
+	Matches(t time.Time) bool
+	Next(t time.Time) (time.Time, error)
+	Prev(t time.Time) (time.Time, error)
+	Resolution() time.Duration
+}
+
+type SetOperation int
+
+const (
+	OpUnion     SetOperation = iota // Fires when either side fires
+	OpIntersect                     // Fires when both sides fire
+	OpSubtract                      // Fires when the left side fires and the right side does not
+)
+
+// CompositeSchedule is the result of a set operation which can not be written as a single cron line
+type CompositeSchedule struct {
+	Operation SetOperation
+	Left      Timetable
+	Right     Timetable
+}
+
+// Dates are compared over the 400 years from 1970, which covers every year field and one whole calendar cycle
+const comparisonYears = 400
+
+// Matches, Next and Prev behave as those of a TaskSpec with this schedule and no location following the clock as it
+// reads, the daylight saving policy is applied once by the TaskSpec holding the schedule
+func (t *TimeSpecExtended) Matches(tm time.Time) bool {
+	spec := TaskSpec{Schedule: *t}
+
+	return spec.wallMismatch(tm) == ""
+}
+
+func (t *TimeSpecExtended) Next(tm time.Time) (time.Time, error) {
+	spec := TaskSpec{Schedule: *t, DST: DSTLiteral}
+
+	return spec.Next(tm)
+}
+
+func (t *TimeSpecExtended) Prev(tm time.Time) (time.Time, error) {
+	spec := TaskSpec{Schedule: *t, DST: DSTLiteral}
+
+	return spec.Prev(tm)
+}
+
+func (t *TimeSpecExtended) Resolution() time.Duration {
+	if t.HasSeconds() {
+		return time.Second
+	}
+
+	return time.Minute
+}
+
+// Union returns a schedule firing when either fires. Schedules which differ in one field, or where one contains the
+// other, give a single TimeSpecExtended. A schedule without seconds is taken to fire at second zero when the other has
+// seconds.
+func (t *TimeSpecExtended) Union(o *TimeSpecExtended) Timetable {
+	if t.IsSubset(o) {
+		return o
+	}
+
+	if o.IsSubset(t) {
+		return t
+	}
+
+	a, b := newFieldSet(t, o), newFieldSet(o, t)
+
+	if field, ok := a.onlyDifference(b); ok && t.EitherDay() == o.EitherDay() {
+		switch field {
+		case TimeUnitDays:
+			a.days = a.days.Union(b.days)
+			a.dayRules = unionRules(a.dayRules, b.dayRules)
+			break
+		case TimeUnitDaysOfWeek:
+			a.daysOfWeek = a.daysOfWeek.Union(b.daysOfWeek)
+			a.dayOfWeekRules = unionRules(a.dayOfWeekRules, b.dayOfWeekRules)
+			break
+		case TimeUnitYears:
+			a.years = a.years.Union(b.years)
+			a.anyYear = a.anyYear || b.anyYear
+			break
+		default:
+			*a.bits(field) = a.bits(field).Union(*b.bits(field))
+		}
+
+		result := a.timeSpec(t, o)
+		return &result
+	}
+
+	return &CompositeSchedule{Operation: OpUnion, Left: t, Right: o}
+}
+
+// Intersect returns a schedule firing when both fire, a single TimeSpecExtended unless both restrict the same calendar
+// dependent field with rules such as L or 15W, or either matches a day by either day field and their day fields differ.
+// Schedules which never fire together give the empty schedule, which renders as EmptySchedule and never fires.
+func (t *TimeSpecExtended) Intersect(o *TimeSpecExtended) Timetable {
+	if result, ok := intersectFields(t, o); ok {
+		if result.isEmpty() {
+			return &TimeSpecExtended{}
+		}
+
+		return single(OpIntersect, result, t, o)
+	}
+
+	return &CompositeSchedule{Operation: OpIntersect, Left: t, Right: o}
+}
+
+// Subtract returns a schedule firing when t fires and o does not. Schedules which never fire together give t and the
+// empty schedule is left when o covers t. When o covers t in every field but one, and that field has no rules, the
+// result is a single TimeSpecExtended unless it would need both day fields to match.
+func (t *TimeSpecExtended) Subtract(o *TimeSpecExtended) Timetable {
+	if both, ok := intersectFields(t, o); ok && both.isEmpty() {
+		return t
+	}
+
+	if t.IsSubset(o) {
+		return &TimeSpecExtended{}
+	}
+
+	if t.EitherDay() || o.EitherDay() {
+		return &CompositeSchedule{Operation: OpSubtract, Left: t, Right: o}
+	}
+
+	a, b := newFieldSet(t, o), newFieldSet(o, t)
+	uncovered := a.uncovered(b)
+
+	if len(uncovered) == 0 {
+		uncovered = []TimeUnitType{TimeUnitMinutes}
+	}
+
+	if len(uncovered) == 1 {
+		switch field := uncovered[0]; field {
+		case TimeUnitDays:
+			if len(a.dayRules) > 0 || len(b.dayRules) > 0 {
+				break
+			}
+
+			a.days = a.days.Subtract(b.days)
+			return single(OpSubtract, a.timeSpec(t, o), t, o)
+		case TimeUnitDaysOfWeek:
+			if len(a.dayOfWeekRules) > 0 || len(b.dayOfWeekRules) > 0 {
+				break
+			}
+
+			a.daysOfWeek = a.daysOfWeek.Subtract(b.daysOfWeek)
+			return single(OpSubtract, a.timeSpec(t, o), t, o)
+		case TimeUnitYears:
+			if a.anyYear {
+				break
+			}
+
+			a.years = a.years.Subtract(b.years)
+			return single(OpSubtract, a.timeSpec(t, o), t, o)
+		default:
+			*a.bits(field) = a.bits(field).Subtract(*b.bits(field))
+			return single(OpSubtract, a.timeSpec(t, o), t, o)
+		}
+	}
+
+	return &CompositeSchedule{Operation: OpSubtract, Left: t, Right: o}
+}
+
+// single returns the result of an operation as one schedule, unless it needs both day fields to match when neither t
+// nor o did. Written as a cron line such a result would match either day field, so the operation is kept as a composite.
+func single(operation SetOperation, result TimeSpecExtended, t *TimeSpecExtended, o *TimeSpecExtended) Timetable {
+	if result.StrictDays && !t.StrictDays && !o.StrictDays {
+		return &CompositeSchedule{Operation: operation, Left: t, Right: o}
+	}
+
+	return &result
+}
+
+// IsSubset reports whether every fire time of t is also a fire time of o. The fields are compared as sets, the dates
+// are compared month by month over a full 400 year calendar cycle when the day fields alone can not tell, and day by
+// day within the month when rules such as L are involved.
+func (t *TimeSpecExtended) IsSubset(o *TimeSpecExtended) bool {
+	if t.isEmpty() {
+		return true
+	}
+
+	a, b := newFieldSet(t, o), newFieldSet(o, t)
+
+	if !a.seconds.IsSubset(b.seconds) || !a.minutes.IsSubset(b.minutes) || !a.hours.IsSubset(b.hours) {
+		return false
+	}
+
+	if a.datesWithin(b) && (!t.EitherDay() || o.EitherDay()) {
+		return true
+	}
+
+	left, right := TaskSpec{Schedule: *t}, TaskSpec{Schedule: *o}
+
+	for year := YearBitsBase; year < YearBitsBase+comparisonYears; year++ {
+		if !left.HasYear(Year(year)) {
+			continue
+		}
+
+		for month := time.January; month <= time.December; month++ {
+			if !left.HasMonth(Month(month)) {
+				continue
+			}
+
+			days := left.daysOf(year, month)
+
+			if days == 0 {
+				continue
+			}
+
+			if !right.HasYear(Year(year)) || !right.HasMonth(Month(month)) || !days.IsSubset(right.daysOf(year, month)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether both schedules fire at exactly the same times, however they were written
+func (t *TimeSpecExtended) Equal(o *TimeSpecExtended) bool {
+	return t.IsSubset(o) && o.IsSubset(t)
+}
+
+// isEmpty reports a schedule which can never fire, either with a field left empty or with days which never occur in
+// its months and years, such as the 30th of February
+func (t *TimeSpecExtended) isEmpty() bool {
+	if t.blank() {
+		return true
+	}
+
+	spec := TaskSpec{Schedule: *t}
+
+	for year := YearBitsBase; year < YearBitsBase+comparisonYears; year++ {
+		if !spec.HasYear(Year(year)) {
+			continue
+		}
+
+		for month := time.January; month <= time.December; month++ {
+			if spec.HasMonth(Month(month)) && spec.daysOf(year, month) != 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// daysOf lists the days of the month which the day fields of the spec allow, ignoring the month and year fields. The
+// day values and days of week are combined as sets, rules such as L are checked day by day.
+func (s *TaskSpec) daysOf(year int, month time.Month) (days Bits) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := daysIn(first)
+
+	if len(s.Schedule.DayRules) > 0 || len(s.Schedule.DayOfWeekRules) > 0 {
+		for day := 1; day <= last; day++ {
+			if s.MatchDays(first.AddDate(0, 0, day-1)) {
+				days = days.Add(day)
+			}
+		}
+
+		return days
+	}
+
+	var byWeekday Bits
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if !s.Schedule.DaysOfWeek.Has(dayOfWeek(int(weekday)).ToInt()) {
+			continue
+		}
+
+		for day := 1 + (int(weekday)-int(first.Weekday())+7)%7; day <= last; day += 7 {
+			byWeekday = byWeekday.Add(day)
+		}
+	}
+
+	byDay := s.Schedule.Days.Intersect(fullBits(1, last))
+
+	if s.Schedule.EitherDay() {
+		return byDay.Union(byWeekday)
+	}
+
+	return byDay.Intersect(byWeekday)
+}
+
+func intersectFields(t *TimeSpecExtended, o *TimeSpecExtended) (TimeSpecExtended, bool) {
+	a, b := newFieldSet(t, o), newFieldSet(o, t)
+
+	if t.EitherDay() || o.EitherDay() {
+		// Days matching either field are kept as they are, so the other side has to allow every day or have the same
+		// day fields
+		switch {
+		case b.anyDay():
+			break
+		case a.anyDay():
+			a.days, a.dayRules, a.daysOfWeek, a.dayOfWeekRules = b.days, b.dayRules, b.daysOfWeek, b.dayOfWeekRules
+			break
+		case !t.EitherDay() || !o.EitherDay() || a.days != b.days || a.daysOfWeek != b.daysOfWeek ||
+			!sameRules(a.dayRules, b.dayRules) || !sameRules(a.dayOfWeekRules, b.dayOfWeekRules):
+			return TimeSpecExtended{}, false
+		}
+
+		result, ok := a.intersectTime(b, t, o)
+		result.StrictDays = false
+
+		return result, ok
+	}
+
+	switch {
+	case len(b.dayRules) == 0 && b.days == fullBits(1, 31):
+		break
+	case len(a.dayRules) == 0 && a.days == fullBits(1, 31):
+		a.days, a.dayRules = b.days, b.dayRules
+		break
+	case len(a.dayRules) == 0 && len(b.dayRules) == 0:
+		a.days = a.days.Intersect(b.days)
+		break
+	default:
+		return TimeSpecExtended{}, false
+	}
+
+	switch {
+	case len(b.dayOfWeekRules) == 0 && b.daysOfWeek == fullBits(1, 7):
+		break
+	case len(a.dayOfWeekRules) == 0 && a.daysOfWeek == fullBits(1, 7):
+		a.daysOfWeek, a.dayOfWeekRules = b.daysOfWeek, b.dayOfWeekRules
+		break
+	case len(a.dayOfWeekRules) == 0 && len(b.dayOfWeekRules) == 0:
+		a.daysOfWeek = a.daysOfWeek.Intersect(b.daysOfWeek)
+		break
+	default:
+		return TimeSpecExtended{}, false
+	}
+
+	return a.intersectTime(b, t, o)
+}
+
+// intersectTime intersects the fields other than the days, which the caller has already combined into f. Seconds or
+// years with no value in common can not be held, as an empty field there stands for second zero or any year, and give
+// the empty schedule.
+func (f fieldSet) intersectTime(o fieldSet, t *TimeSpecExtended, other *TimeSpecExtended) (TimeSpecExtended, bool) {
+	if f.anyYear {
+		f.years, f.anyYear = o.years, o.anyYear
+	} else if !o.anyYear {
+		f.years = f.years.Intersect(o.years)
+	}
+
+	f.seconds = f.seconds.Intersect(o.seconds)
+	f.minutes = f.minutes.Intersect(o.minutes)
+	f.hours = f.hours.Intersect(o.hours)
+	f.months = f.months.Intersect(o.months)
+
+	if f.hasSeconds && f.seconds == 0 || !f.anyYear && f.years == (YearBits{}) {
+		return TimeSpecExtended{}, true
+	}
+
+	return f.timeSpec(t, other), true
+}
+
+// fieldSet is a schedule taken apart for set operations, the seconds are set to zero when the schedule has none but the
+// one it is combined with does
+type fieldSet struct {
+	seconds, minutes, hours, days, months, daysOfWeek Bits
+	dayRules, dayOfWeekRules                          []DayRule
+	years                                             YearBits
+	hasSeconds, anyYear                               bool
+}
+
+func newFieldSet(t *TimeSpecExtended, other *TimeSpecExtended) fieldSet {
+	f := fieldSet{
+		seconds:        t.Seconds,
+		minutes:        t.Minutes,
+		hours:          t.Hours,
+		days:           t.Days,
+		months:         t.Months,
+		daysOfWeek:     t.DaysOfWeek,
+		dayRules:       t.DayRules,
+		dayOfWeekRules: t.DayOfWeekRules,
+		years:          t.Years,
+		hasSeconds:     t.HasSeconds() || other.HasSeconds(),
+		anyYear:        t.AnyYear(),
+	}
+
+	if !t.HasSeconds() {
+		f.seconds = Bits(0).Add(0)
+	}
+
+	return f
+}
+
+// datesWithin reports year, month and day fields each within those of o, which with no rules on either side puts every
+// date of f within the dates of o unless f matches either day field and o needs both
+func (f fieldSet) datesWithin(o fieldSet) bool {
+	if len(f.dayRules) > 0 || len(f.dayOfWeekRules) > 0 || len(o.dayRules) > 0 || len(o.dayOfWeekRules) > 0 {
+		return false
+	}
+
+	if !o.anyYear && (f.anyYear || !f.years.IsSubset(o.years)) {
+		return false
+	}
+
+	return f.months.IsSubset(o.months) && f.days.IsSubset(o.days) && f.daysOfWeek.IsSubset(o.daysOfWeek)
+}
+
+// anyDay reports day fields which allow every date
+func (f fieldSet) anyDay() bool {
+	return f.days == fullBits(1, 31) && f.daysOfWeek == fullBits(1, 7) && len(f.dayRules) == 0 && len(f.dayOfWeekRules) == 0
+}
+
+func (f *fieldSet) bits(field TimeUnitType) *Bits {
+	switch field {
+	case TimeUnitSeconds:
+		return &f.seconds
+	case TimeUnitMinutes:
+		return &f.minutes
+	case TimeUnitHours:
+		return &f.hours
+	case TimeUnitMonths:
+		return &f.months
+	}
+
+	return nil
+}
+
+// uncovered lists the fields of f with values outside those of o, day rules only count as covered when o has the
+// same rule
+func (f fieldSet) uncovered(o fieldSet) (fields []TimeUnitType) {
+	if !f.seconds.IsSubset(o.seconds) {
+		fields = append(fields, TimeUnitSeconds)
+	}
+
+	if !f.minutes.IsSubset(o.minutes) {
+		fields = append(fields, TimeUnitMinutes)
+	}
+
+	if !f.hours.IsSubset(o.hours) {
+		fields = append(fields, TimeUnitHours)
+	}
+
+	if !f.days.IsSubset(o.days) || len(unionRules(o.dayRules, f.dayRules)) != len(o.dayRules) {
+		fields = append(fields, TimeUnitDays)
+	}
+
+	if !f.months.IsSubset(o.months) {
+		fields = append(fields, TimeUnitMonths)
+	}
+
+	if !f.daysOfWeek.IsSubset(o.daysOfWeek) || len(unionRules(o.dayOfWeekRules, f.dayOfWeekRules)) != len(o.dayOfWeekRules) {
+		fields = append(fields, TimeUnitDaysOfWeek)
+	}
+
+	if !o.anyYear && (f.anyYear || !f.years.IsSubset(o.years)) {
+		fields = append(fields, TimeUnitYears)
+	}
+
+	return fields
+}
+
+// onlyDifference finds the single field in which two schedules differ, ok is false when they differ in more than one
+func (f fieldSet) onlyDifference(o fieldSet) (field TimeUnitType, ok bool) {
+	var differences []TimeUnitType
+
+	if f.seconds != o.seconds {
+		differences = append(differences, TimeUnitSeconds)
+	}
+
+	if f.minutes != o.minutes {
+		differences = append(differences, TimeUnitMinutes)
+	}
+
+	if f.hours != o.hours {
+		differences = append(differences, TimeUnitHours)
+	}
+
+	if f.days != o.days || !sameRules(f.dayRules, o.dayRules) {
+		differences = append(differences, TimeUnitDays)
+	}
+
+	if f.months != o.months {
+		differences = append(differences, TimeUnitMonths)
+	}
+
+	if f.daysOfWeek != o.daysOfWeek || !sameRules(f.dayOfWeekRules, o.dayOfWeekRules) {
+		differences = append(differences, TimeUnitDaysOfWeek)
+	}
+
+	if f.anyYear != o.anyYear || f.years != o.years {
+		differences = append(differences, TimeUnitYears)
+	}
+
+	if len(differences) != 1 {
+		return 0, false
+	}
+
+	return differences[0], true
+}
+
+// timeSpec builds the schedule back from its fields, a field keeps its * only when it had one in both schedules. Days
+// match by either day field only when they did in both schedules.
+func (f fieldSet) timeSpec(t *TimeSpecExtended, o *TimeSpecExtended) TimeSpecExtended {
+	result := TimeSpecExtended{
+		Minutes:        f.minutes,
+		Hours:          f.hours,
+		Days:           f.days,
+		Months:         f.months,
+		DaysOfWeek:     f.daysOfWeek,
+		DayRules:       f.dayRules,
+		DayOfWeekRules: f.dayOfWeekRules,
+		MinuteStar:     t.MinuteStar && o.MinuteStar,
+		HourStar:       t.HourStar && o.HourStar,
+		DayStar:        t.DayStar && o.DayStar,
+		DayOfWeekStar:  t.DayOfWeekStar && o.DayOfWeekStar,
+	}
+
+	if !result.DayStar && !result.DayOfWeekStar {
+		result.StrictDays = !t.EitherDay() || !o.EitherDay()
+	}
+
+	if f.hasSeconds {
+		result.Seconds = f.seconds
+	}
+
+	if !f.anyYear {
+		result.Years = f.years
+	}
+
+	return result
+}
+
+func sameRules(a []DayRule, b []DayRule) bool {
+	return len(unionRules(a, b)) == len(a) && len(a) == len(b)
+}
+
+func unionRules(a []DayRule, b []DayRule) []DayRule {
+	rules := append([]DayRule{}, a...)
+
+	for _, rule := range b {
+		found := false
+
+		for i := range rules {
+			if rules[i] == rule {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func (c *CompositeSchedule) Resolution() time.Duration {
+	if left, right := c.Left.Resolution(), c.Right.Resolution(); right < left {
+		return right
+	}
+
+	return c.Left.Resolution()
+}
+
+// Matches checks t against both sides, a side without seconds only matches on the minute when the other has seconds
+func (c *CompositeSchedule) Matches(t time.Time) bool {
+	step := c.Resolution()
+
+	switch c.Operation {
+	case OpUnion:
+		return matchesAt(c.Left, t, step) || matchesAt(c.Right, t, step)
+	case OpIntersect:
+		return matchesAt(c.Left, t, step) && matchesAt(c.Right, t, step)
+	case OpSubtract:
+		return matchesAt(c.Left, t, step) && !matchesAt(c.Right, t, step)
+	}
+
+	return false
+}
+
+func matchesAt(timetable Timetable, t time.Time, step time.Duration) bool {
+	if resolution := timetable.Resolution(); resolution > step && !t.Equal(t.Truncate(resolution)) {
+		return false
+	}
+
+	return timetable.Matches(t)
+}
+
+// Next returns the first time after t at which the composite fires. Intersections leapfrog between the next times of
+// each side, differences step through the left side skipping times the right side also has.
+func (c *CompositeSchedule) Next(t time.Time) (time.Time, error) {
+	step := c.Resolution()
+	limit := t.Year() + maxSearchYears
+
+	switch c.Operation {
+	case OpUnion:
+		left, leftErr := c.Left.Next(t)
+		right, rightErr := c.Right.Next(t)
+
+		if leftErr != nil || (rightErr == nil && right.Before(left)) {
+			return right, rightErr
+		}
+
+		return left, nil
+	case OpIntersect:
+		for {
+			left, err := c.Left.Next(t)
+
+			if err != nil || left.Year() > limit {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if matchesAt(c.Right, left, step) {
+				return left, nil
+			}
+
+			right, err := c.Right.Next(left.Add(-time.Nanosecond))
+
+			if err != nil {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if matchesAt(c.Left, right, step) {
+				return right, nil
+			}
+
+			t = right
+		}
+	case OpSubtract:
+		for {
+			left, err := c.Left.Next(t)
+
+			if err != nil || left.Year() > limit {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if !matchesAt(c.Right, left, step) {
+				return left, nil
+			}
+
+			t = left
+		}
+	}
+
+	return time.Time{}, ErrNeverFires
+}
+
+// Prev returns the last time before t at which the composite fires
+func (c *CompositeSchedule) Prev(t time.Time) (time.Time, error) {
+	step := c.Resolution()
+	limit := t.Year() - maxSearchYears
+
+	switch c.Operation {
+	case OpUnion:
+		left, leftErr := c.Left.Prev(t)
+		right, rightErr := c.Right.Prev(t)
+
+		if leftErr != nil || (rightErr == nil && right.After(left)) {
+			return right, rightErr
+		}
+
+		return left, nil
+	case OpIntersect:
+		for {
+			left, err := c.Left.Prev(t)
+
+			if err != nil || left.Year() < limit {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if matchesAt(c.Right, left, step) {
+				return left, nil
+			}
+
+			right, err := c.Right.Prev(left.Add(time.Nanosecond))
+
+			if err != nil {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if matchesAt(c.Left, right, step) {
+				return right, nil
+			}
+
+			t = right
+		}
+	case OpSubtract:
+		for {
+			left, err := c.Left.Prev(t)
+
+			if err != nil || left.Year() < limit {
+				return time.Time{}, ErrNeverFires
+			}
+
+			if !matchesAt(c.Right, left, step) {
+				return left, nil
+			}
+
+			t = left
+		}
+	}
+
+	return time.Time{}, ErrNeverFires
+}
+
+// Union, Intersect and Subtract combine a composite further
+func (c *CompositeSchedule) Union(o Timetable) *CompositeSchedule {
+	return &CompositeSchedule{Operation: OpUnion, Left: c, Right: o}
+}
+
+func (c *CompositeSchedule) Intersect(o Timetable) *CompositeSchedule {
+	return &CompositeSchedule{Operation: OpIntersect, Left: c, Right: o}
+}
+
+func (c *CompositeSchedule) Subtract(o Timetable) *CompositeSchedule {
+	return &CompositeSchedule{Operation: OpSubtract, Left: c, Right: o}
+}
@@ -0,0 +1,153 @@
+package specparser
+
+import "math/bits"
+
+// Bits is a set of values from 0 to 63 held as one bit per value, wide enough for every field except years
+type Bits uint64
+
+// YearBits holds the years from YearBitsBase as one bit per year
+type YearBits [3]uint64
+
+const YearBitsBase = 1970
+
+// NewBits holds the values of time units, such as the Days sequence, as a bitmask
+func NewBits(values []TimeUnit) (b Bits) {
+	for i := range values {
+		b = b.Add(values[i].ToInt())
+	}
+
+	return b
+}
+
+func bitsOf(values []int) (b Bits) {
+	for _, i := range values {
+		b = b.Add(i)
+	}
+
+	return b
+}
+
+func (b Bits) Add(i int) Bits {
+	if i < 0 || i > 63 {
+		return b
+	}
+
+	return b | 1<<uint(i)
+}
+
+func (b Bits) Has(i int) bool {
+	return i >= 0 && i < 64 && b&(1<<uint(i)) != 0
+}
+
+func (b Bits) Union(o Bits) Bits {
+	return b | o
+}
+
+func (b Bits) Intersect(o Bits) Bits {
+	return b & o
+}
+
+func (b Bits) Subtract(o Bits) Bits {
+	return b &^ o
+}
+
+func (b Bits) IsSubset(o Bits) bool {
+	return b&^o == 0
+}
+
+func (b Bits) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// Values lists the members in ascending order
+func (b Bits) Values() (values []int) {
+	for b != 0 {
+		i := bits.TrailingZeros64(uint64(b))
+		values = append(values, i)
+		b &^= 1 << uint(i)
+	}
+
+	return values
+}
+
+func NewYearBits(values []TimeUnit) (y YearBits) {
+	for i := range values {
+		y = y.Add(values[i].ToInt())
+	}
+
+	return y
+}
+
+func (y YearBits) Add(year int) YearBits {
+	i := year - YearBitsBase
+
+	if i < 0 || i >= len(y)*64 {
+		return y
+	}
+
+	y[i/64] |= 1 << uint(i%64)
+	return y
+}
+
+func (y YearBits) Has(year int) bool {
+	i := year - YearBitsBase
+
+	return i >= 0 && i < len(y)*64 && y[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (y YearBits) Union(o YearBits) (r YearBits) {
+	for i := range y {
+		r[i] = y[i] | o[i]
+	}
+
+	return r
+}
+
+func (y YearBits) Intersect(o YearBits) (r YearBits) {
+	for i := range y {
+		r[i] = y[i] & o[i]
+	}
+
+	return r
+}
+
+func (y YearBits) Subtract(o YearBits) (r YearBits) {
+	for i := range y {
+		r[i] = y[i] &^ o[i]
+	}
+
+	return r
+}
+
+func (y YearBits) IsSubset(o YearBits) bool {
+	return y.Subtract(o) == YearBits{}
+}
+
+func (y YearBits) Count() (count int) {
+	for i := range y {
+		count += bits.OnesCount64(y[i])
+	}
+
+	return count
+}
+
+// Values lists the member years in ascending order
+func (y YearBits) Values() (values []int) {
+	for i := range y {
+		for _, value := range Bits(y[i]).Values() {
+			values = append(values, YearBitsBase+i*64+value)
+		}
+	}
+
+	return values
+}
+
+// HasSeconds reports a schedule with second resolution, one without a seconds field fires at second zero
+func (t *TimeSpecExtended) HasSeconds() bool {
+	return t.Seconds != 0
+}
+
+// AnyYear reports a schedule without a year field
+func (t *TimeSpecExtended) AnyYear() bool {
+	return t.Years == YearBits{}
+}
@@ -0,0 +1,367 @@
+package specparser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalendarShorthands maps the systemd OnCalendar shorthands onto their full form
+var CalendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+var (
+	calendarValuePattern   = regexp.MustCompile(`^[0-9*,/-]+$`)
+	calendarWeekdayPattern = regexp.MustCompile(`^[A-Za-z]+(\.\.[A-Za-z]+)?(,[A-Za-z]+(\.\.[A-Za-z]+)?)*$`)
+	calendarWeekdays       = []string{"", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+)
+
+// NewTaskSpecCalendar parses a systemd OnCalendar expression, [weekdays] [year-]month-day [hour:minute[:second]]
+// [time zone], into a spec running command. Ranges are written a..b, ~n in the day is the n-th last day of the month and
+// a missing time is midnight. As in systemd a date has to match both the weekdays and the day of month. The seconds are
+// only kept when they are not zero, so most expressions have minute resolution.
+func NewTaskSpecCalendar(calendar string, command string) (taskSpec TaskSpec, err error) {
+	parts, offsets := fields(calendar)
+
+	if len(parts) == 0 {
+		return taskSpec, &ParseError{Field: "calendar", Offset: len(calendar), Reason: ReasonFieldCount, Message: "empty calendar expression"}
+	}
+
+	if expanded, ok := CalendarShorthands[strings.ToLower(parts[0])]; ok {
+		// The parts of the full form are all reported at the shorthand
+		shorthand, _ := fields(expanded)
+		parts = append(shorthand, parts[1:]...)
+
+		for len(offsets) < len(parts) {
+			offsets = append([]int{offsets[0]}, offsets...)
+		}
+	}
+
+	timeExpression := TimeExpression{Second: "0", Minute: "0", Hour: "0", Day: "*", Month: "*", DayOfWeek: "*", Year: "*"}
+	components := make(map[string]int) // The part each field was read from
+
+	for i, part := range parts {
+		var names []string
+		var err error
+
+		switch {
+		case strings.Contains(part, ":"):
+			names, err = []string{"second", "minute", "hour"}, timeExpression.calendarTime(part)
+			break
+		case i == 0 && calendarWeekdayPattern.MatchString(part):
+			names, err = []string{"day-of-week"}, timeExpression.calendarWeekdays(part)
+			break
+		case strings.IndexAny(part, "*0123456789") == 0:
+			names, err = []string{"year", "month", "day"}, timeExpression.calendarDate(part)
+			break
+		case i > 0 && i == len(parts)-1:
+			if taskSpec.Location, err = LoadLocation(part); err != nil {
+				err = errors.New("unknown time zone")
+			}
+			break
+		default:
+			err = errors.New("invalid calendar component")
+		}
+
+		if err != nil {
+			return taskSpec, &ParseError{Field: "calendar", Offset: offsets[i], Token: part, Reason: ReasonInvalidValue, Message: err.Error()}
+		}
+
+		for _, name := range names {
+			components[name] = i
+		}
+	}
+
+	schedule, err := timeExpression.Explode()
+
+	if parseError, ok := err.(*ParseError); ok {
+		// Offsets within the translated field do not apply to what was written, the whole component is reported
+		if i, ok := components[parseError.Field]; ok {
+			parseError.Offset, parseError.Token = offsets[i], parts[i]
+		}
+
+		return taskSpec, parseError
+	}
+
+	if schedule.Seconds == Bits(0).Add(0) {
+		schedule.Seconds = 0
+	}
+
+	schedule.StrictDays = true
+
+	taskSpec.Expression = strings.Join(strings.Fields(calendar), " ")
+	taskSpec.Schedule = schedule
+	taskSpec.Command = command
+
+	return taskSpec, taskSpec.checkCommand(0)
+}
+
+// calendarTime reads hour:minute[:second]
+func (t *TimeExpression) calendarTime(part string) error {
+	components := strings.Split(part, ":")
+
+	if len(components) < 2 || len(components) > 3 {
+		return errors.New("time is hour:minute or hour:minute:second")
+	}
+
+	values := make([]ValueExpression, 3)
+	values[2] = "0"
+
+	for i := range components {
+		var err error
+
+		if values[i], err = calendarValue(components[i]); err != nil {
+			return err
+		}
+	}
+
+	t.Hour, t.Minute, t.Second = values[0], values[1], values[2]
+
+	return nil
+}
+
+// calendarDate reads [year-]month-day where the day may instead be given from the end of the month as month~n
+func (t *TimeExpression) calendarDate(part string) error {
+	var day ValueExpression
+	var err error
+
+	datePart := part
+
+	if i := strings.Index(part, "~"); i >= 0 {
+		datePart = part[:i] + "-"
+
+		if day, err = calendarLastDays(part[i+1:]); err != nil {
+			return err
+		}
+	}
+
+	components := strings.Split(datePart, "-")
+
+	if len(components) == 2 {
+		components = append([]string{"*"}, components...)
+	}
+
+	if len(components) != 3 {
+		return errors.New("date is year-month-day or month-day")
+	}
+
+	if day == "" {
+		if day, err = calendarValue(components[2]); err != nil {
+			return err
+		}
+	}
+
+	if t.Year, err = calendarValue(components[0]); err != nil {
+		return err
+	}
+
+	if t.Month, err = calendarValue(components[1]); err != nil {
+		return err
+	}
+
+	t.Day = day
+
+	return nil
+}
+
+// calendarLastDays converts ~n, the n-th last day of the month, to L-(n-1)
+func calendarLastDays(component string) (ValueExpression, error) {
+	var items []string
+
+	for _, item := range strings.Split(component, ",") {
+		n, err := strconv.Atoi(item)
+
+		if err != nil || n < 1 || n > 31 {
+			return "", errors.New("invalid day from the end of the month " + strconv.Quote(item))
+		}
+
+		if n == 1 {
+			items = append(items, "L")
+		} else {
+			items = append(items, "L-"+strconv.Itoa(n-1))
+		}
+	}
+
+	return ValueExpression(strings.Join(items, ",")), nil
+}
+
+// calendarWeekdays reads a list of weekday names and name..name ranges, names are the english names of the days or
+// their first three letters. As in systemd a range does not wrap past sunday, so Sun..Mon is rejected.
+func (t *TimeExpression) calendarWeekdays(part string) error {
+	var items []string
+
+	for _, item := range strings.Split(part, ",") {
+		var days []int
+
+		for _, name := range strings.Split(item, "..") {
+			day := calendarWeekday(name)
+
+			if day == 0 {
+				return errors.New("invalid weekday " + strconv.Quote(name))
+			}
+
+			days = append(days, day)
+		}
+
+		if len(days) == 2 && days[0] > days[1] {
+			return errors.New("weekday range " + strconv.Quote(item) + " runs backwards")
+		}
+
+		if len(days) == 2 {
+			items = append(items, strconv.Itoa(days[0])+"-"+strconv.Itoa(days[1]))
+		} else {
+			items = append(items, strconv.Itoa(days[0]))
+		}
+	}
+
+	t.DayOfWeek = ValueExpression(strings.Join(items, ","))
+
+	return nil
+}
+
+// calendarWeekday numbers a weekday name from 1 for monday to 7 for sunday, zero when the name is not a weekday
+func calendarWeekday(name string) int {
+	for i := 1; i <= 7; i++ {
+		if strings.EqualFold(name, calendarWeekdays[i]) || strings.EqualFold(name, time.Weekday(i%7).String()) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// calendarValue converts a systemd value, where ranges are written a..b, to a cron value
+func calendarValue(component string) (ValueExpression, error) {
+	if strings.Contains(component, "-") || strings.Contains(component, ".") && !strings.Contains(component, "..") {
+		return "", errors.New("invalid value " + strconv.Quote(component))
+	}
+
+	value := strings.Replace(component, "..", "-", -1)
+
+	if !calendarValuePattern.MatchString(value) {
+		return "", errors.New("invalid value " + strconv.Quote(component))
+	}
+
+	return ValueExpression(value), nil
+}
+
+// OnCalendar renders the spec as a systemd OnCalendar expression, an error explains what has no equivalent, such as a
+// date matching either day field or a W rule
+func (s *TaskSpec) OnCalendar() (string, error) {
+	schedule := &s.Schedule
+
+	switch {
+	case s.Reboot:
+		return "", errors.New("@reboot has no calendar equivalent")
+	case s.Timetable != nil:
+		return "", errors.New("a timetable has no calendar equivalent")
+	case schedule.EitherDay():
+		return "", errors.New("a date matching either day field has no calendar equivalent, systemd requires both")
+	case len(schedule.DayOfWeekRules) > 0:
+		return "", errors.New("L and # in the day of week have no calendar equivalent")
+	}
+
+	days := "-" + calendarField(schedule.Days.Values(), 1, 31, 2)
+
+	if len(schedule.DayRules) > 0 {
+		var items []string
+
+		if schedule.Days != 0 {
+			return "", errors.New("days mixed with L have no calendar equivalent")
+		}
+
+		// The n-th last day of the month is written ~n, L is the first last day
+		for _, rule := range schedule.DayRules {
+			if rule.Kind != LastDayOfMonth {
+				return "", errors.New("W rules have no calendar equivalent")
+			}
+
+			items = append(items, strconv.Itoa(rule.Offset+1))
+		}
+
+		days = "~" + strings.Join(items, ",")
+	}
+
+	year := "*"
+
+	if !schedule.AnyYear() {
+		year = calendarField(schedule.Years.Values(), YearBitsBase, 2099, 4)
+	}
+
+	seconds := "00"
+
+	if schedule.HasSeconds() {
+		seconds = calendarField(schedule.Seconds.Values(), 0, 59, 2)
+	}
+
+	var parts []string
+
+	if schedule.DaysOfWeek != fullBits(1, 7) {
+		var items []string
+
+		for _, run := range runs(schedule.DaysOfWeek.Values(), 3) {
+			if run[0] == run[1] {
+				items = append(items, calendarWeekdays[run[0]])
+			} else {
+				items = append(items, calendarWeekdays[run[0]]+".."+calendarWeekdays[run[1]])
+			}
+		}
+
+		parts = append(parts, strings.Join(items, ","))
+	}
+
+	parts = append(parts,
+		year+"-"+calendarField(schedule.Months.Values(), 1, 12, 2)+days,
+		calendarField(schedule.Hours.Values(), 0, 23, 2)+":"+calendarField(schedule.Minutes.Values(), 0, 59, 2)+":"+seconds,
+	)
+
+	if s.Location != nil {
+		parts = append(parts, s.Location.String())
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// calendarField renders values as *, a/s, or a list of values and a..b ranges, zero padded to width
+func calendarField(values []int, min int, max int, width int) string {
+	pad := func(i int) string {
+		value := strconv.Itoa(i)
+
+		for len(value) < width {
+			value = "0" + value
+		}
+
+		return value
+	}
+
+	if len(values) == max-min+1 {
+		return "*"
+	}
+
+	if step, start, ok := steps(values, min, max); ok {
+		return pad(start) + "/" + strconv.Itoa(step)
+	}
+
+	var items []string
+
+	for _, run := range runs(values, 3) {
+		if run[0] == run[1] {
+			items = append(items, pad(run[0]))
+		} else {
+			items = append(items, pad(run[0])+".."+pad(run[1]))
+		}
+	}
+
+	return strings.Join(items, ",")
+}
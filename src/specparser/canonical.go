@@ -0,0 +1,238 @@
+package specparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EmptySchedule is what String renders for a schedule with a field left empty, such as an intersection of schedules with
+// no minute in common, which never fires and has no expression. It does not parse.
+const EmptySchedule = "@never"
+
+// String renders the schedule as the shortest expression with the same fields, so 0,1,2,3 and 0-3 both become 0-3.
+// Seconds lead and a year follows only when the schedule has them, the result parses back with ParseSeconds and
+// ParseYears. Day of week uses 0 or 7 for sunday, whichever is shorter. Minutes, hours, days and days of week written
+// starting with * keep it, as daylight saving treats them differently, full seconds and months are always *. A schedule
+// with StrictDays and both day fields restricted reads back the same only with ParseStrictDays.
+func (t *TimeSpecExtended) String() string {
+	var fields []string
+
+	if t.blank() {
+		return EmptySchedule
+	}
+
+	if t.HasSeconds() {
+		fields = append(fields, renderField(t.Seconds.Values(), 0, 59, true))
+	}
+
+	fields = append(fields,
+		renderField(t.Minutes.Values(), 0, 59, t.MinuteStar),
+		renderField(t.Hours.Values(), 0, 23, t.HourStar),
+		renderDays(t.Days.Values(), t.DayRules, t.DayStar),
+		renderField(t.Months.Values(), 1, 12, true),
+		renderDaysOfWeek(t.DaysOfWeek.Values(), t.DayOfWeekRules, t.DayOfWeekStar),
+	)
+
+	if !t.AnyYear() {
+		fields = append(fields, renderField(t.Years.Values(), YearBitsBase, 2099, false))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// String renders the schedule of the spec as TimeSpecExtended.String does, a spec run at startup renders as @reboot
+func (s *TaskSpec) String() string {
+	if s.Reboot {
+		return MacroReboot
+	}
+
+	return s.Schedule.String()
+}
+
+// blank reports a schedule with a time field or both day fields left empty, or either day field when both have to
+// match, so that it can never fire
+func (t *TimeSpecExtended) blank() bool {
+	noDays := t.Days == 0 && len(t.DayRules) == 0
+	noDaysOfWeek := t.DaysOfWeek == 0 && len(t.DayOfWeekRules) == 0
+
+	if t.Minutes == 0 || t.Hours == 0 || t.Months == 0 || noDays && noDaysOfWeek {
+		return true
+	}
+
+	return !t.EitherDay() && (noDays || noDaysOfWeek)
+}
+
+// Key identifies a job by what it runs and when, two jobs with the same key are duplicates however their specs were
+// written
+func (s *TaskSpec) Key() string {
+	location := ""
+
+	if s.Location != nil {
+		location = s.Location.String()
+	}
+
+	return strings.Join([]string{s.String(), location, s.User, strconv.Quote(s.Command), strconv.Quote(s.Stdin)}, "\t")
+}
+
+// renderField picks the shortest list of values, ranges a-b and steps a-b/s. A starred field which is full or an exact
+// */s is rendered that way so the star is kept.
+func renderField(values []int, min int, max int, star bool) string {
+	if star {
+		if len(values) == max-min+1 {
+			return "*"
+		}
+
+		if step, start, ok := steps(values, min, max); ok && start == min {
+			return "*/" + strconv.Itoa(step)
+		}
+	}
+
+	if !star && len(values) == max-min+1 && max-min > 0 {
+		// A full field which was not written as * stays restricted
+		return strconv.Itoa(min) + "-" + strconv.Itoa(max)
+	}
+
+	return shortestList(values, max)
+}
+
+// shortestList splits the ascending values into consecutive segments, each rendered as a single value, a range or a
+// step, and returns the shortest combination
+func shortestList(values []int, max int) string {
+	n := len(values)
+	best := make([]string, n+1)
+
+	for i := n - 1; i >= 0; i-- {
+		best[i] = ""
+
+		for j := i; j < n; j++ {
+			segment, ok := renderSegment(values[i:j+1], max)
+
+			if !ok {
+				continue
+			}
+
+			candidate := segment
+
+			if j+1 < n {
+				candidate += "," + best[j+1]
+			}
+
+			if best[i] == "" || len(candidate) < len(best[i]) {
+				best[i] = candidate
+			}
+		}
+	}
+
+	return best[0]
+}
+
+// renderSegment renders values as one item, ok is false when they are neither a single value, a range nor a step. A step
+// which would carry on past the field runs to max, written 5-59/10 rather than 5/10 which Vixie cron does not read.
+func renderSegment(values []int, max int) (string, bool) {
+	first, final := values[0], values[len(values)-1]
+
+	switch {
+	case len(values) == 1:
+		return strconv.Itoa(first), true
+	case final-first == len(values)-1:
+		return strconv.Itoa(first) + "-" + strconv.Itoa(final), true
+	case len(values) < 3:
+		return "", false
+	}
+
+	step := values[1] - values[0]
+
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return "", false
+		}
+	}
+
+	if final+step > max {
+		final = max
+	}
+
+	return strconv.Itoa(first) + "-" + strconv.Itoa(final) + "/" + strconv.Itoa(step), true
+}
+
+func renderDays(days []int, rules []DayRule, star bool) string {
+	var items []string
+
+	if len(days) > 0 {
+		items = append(items, renderField(days, 1, 31, star && len(rules) == 0))
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LastDayOfMonth:
+			if rule.Offset == 0 {
+				items = append(items, "L")
+			} else {
+				items = append(items, "L-"+strconv.Itoa(rule.Offset))
+			}
+			break
+		case NearestWeekday:
+			items = append(items, strconv.Itoa(rule.Day.ToInt())+"W")
+			break
+		case LastWeekdayOfMonth:
+			items = append(items, "LW")
+			break
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+// starStep finds the step of days of week numbered from sunday as zero which */step gives in cron
+func starStep(fromZero []int) (int, bool) {
+	step := 7
+
+	if len(fromZero) > 1 {
+		step = fromZero[1]
+	}
+
+	for i, day := range fromZero {
+		if day != i*step {
+			return 0, false
+		}
+	}
+
+	return step, (len(fromZero)-1)*step+step > 6
+}
+
+func renderDaysOfWeek(daysOfWeek []int, rules []DayRule, star bool) string {
+	var items []string
+
+	if len(daysOfWeek) > 0 {
+		// Sunday is held as 7, numbering it 0 gives the more familiar form when that is no longer
+		item := renderField(daysOfWeek, 1, 7, star && len(rules) == 0 && len(daysOfWeek) == 7)
+		fromZero := daysOfWeek
+
+		if daysOfWeek[len(daysOfWeek)-1] == 7 {
+			fromZero = append([]int{0}, daysOfWeek[:len(daysOfWeek)-1]...)
+		}
+
+		if step, ok := starStep(fromZero); ok && star && len(rules) == 0 && len(daysOfWeek) < 7 {
+			item = "*/" + strconv.Itoa(step)
+		} else if !strings.HasPrefix(item, "*") && fromZero[0] == 0 {
+			if alternative := shortestList(fromZero, 6); len(alternative) <= len(item) {
+				item = alternative
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LastDayOfWeek:
+			items = append(items, strconv.Itoa(rule.DayOfWeek.ToInt()%7)+"L")
+			break
+		case NthDayOfWeek:
+			items = append(items, strconv.Itoa(rule.DayOfWeek.ToInt()%7)+"#"+strconv.Itoa(rule.Nth))
+			break
+		}
+	}
+
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,150 @@
+package specparser
+
+import (
+	"sort"
+	"time"
+)
+
+// Collision is a time slot at which two or more jobs fire together
+type Collision struct {
+	Time  time.Time
+	Tasks []*TaskSpec
+}
+
+// Overlap is a period in which the same two or more job runs are in progress, the runs last for their recorded durations
+type Overlap struct {
+	Start time.Time
+	End   time.Time
+	Runs  []Run
+}
+
+// Run is one firing of a job and the time it is expected to finish
+type Run struct {
+	Task  *TaskSpec
+	Start time.Time
+	End   time.Time
+}
+
+type CollisionReport struct {
+	Start      time.Time
+	End        time.Time
+	Collisions []Collision
+	Overlaps   []Overlap
+
+	PeakSlot            int       // Most jobs firing in a single slot
+	PeakSlotTime        time.Time // First slot at which PeakSlot jobs fire
+	PeakConcurrency     int       // Most runs in progress at once, zero without durations
+	PeakConcurrencyTime time.Time // When PeakConcurrency was first reached
+}
+
+// NewCollisionReport lists every slot of the window in which two or more of the tasks fire. Durations are keyed by
+// TaskSpec.Key, when a task has one its runs are taken to last that long and the periods in which runs overlap are
+// reported as well. Tasks without a duration take no time and never overlap.
+func NewCollisionReport(tasks []TaskSpec, t time.Time, lookAheadMins int, durations map[string]time.Duration) (report CollisionReport, err error) {
+	var taskList TaskList
+	var runs []Run
+
+	report.Start = t
+	report.End = t.Add(time.Minute * time.Duration(lookAheadMins))
+
+	for i := range tasks {
+		list, err := NewTaskList(tasks[i], t, lookAheadMins)
+
+		if err != nil {
+			return report, err
+		}
+
+		taskList.Merge(list)
+	}
+
+	for _, slot := range taskList.Schedule {
+		work := taskList.Work[slot]
+
+		if len(work) > report.PeakSlot {
+			report.PeakSlot, report.PeakSlotTime = len(work), slot
+		}
+
+		if len(work) > 1 {
+			report.Collisions = append(report.Collisions, Collision{Time: slot, Tasks: work})
+		}
+
+		for _, task := range work {
+			if duration := durations[task.Key()]; duration > 0 {
+				runs = append(runs, Run{Task: task, Start: slot, End: slot.Add(duration)})
+			}
+		}
+	}
+
+	report.Overlaps, report.PeakConcurrency, report.PeakConcurrencyTime = overlaps(runs)
+
+	return report, nil
+}
+
+// Collisions reports the jobs of the crontab which fire or run together in the window starting at t
+func (c *Crontab) Collisions(t time.Time, lookAheadMins int, durations map[string]time.Duration) (CollisionReport, error) {
+	return NewCollisionReport(c.Tasks, t, lookAheadMins, durations)
+}
+
+// overlaps sweeps through the start and end of every run, each change to the set of runs in progress while two or more
+// are in progress ends one overlap and starts the next
+func overlaps(runs []Run) (result []Overlap, peak int, peakTime time.Time) {
+	type event struct {
+		time  time.Time
+		start bool
+		run   int
+	}
+
+	var events []event
+	var active []int
+
+	for i := range runs {
+		events = append(events, event{runs[i].Start, true, i}, event{runs[i].End, false, i})
+	}
+
+	// Runs ending at the moment another starts do not overlap it
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].time.Equal(events[j].time) {
+			return events[i].time.Before(events[j].time)
+		}
+
+		return !events[i].start && events[j].start
+	})
+
+	for i := 0; i < len(events); {
+		now := events[i].time
+
+		if len(active) > 1 {
+			result[len(result)-1].End = now
+		}
+
+		for ; i < len(events) && events[i].time.Equal(now); i++ {
+			if events[i].start {
+				active = append(active, events[i].run)
+				continue
+			}
+
+			for k := range active {
+				if active[k] == events[i].run {
+					active = append(active[:k], active[k+1:]...)
+					break
+				}
+			}
+		}
+
+		if len(active) > peak {
+			peak, peakTime = len(active), now
+		}
+
+		if len(active) > 1 {
+			overlap := Overlap{Start: now}
+
+			for _, run := range active {
+				overlap.Runs = append(overlap.Runs, runs[run])
+			}
+
+			result = append(result, overlap)
+		}
+	}
+
+	return result, peak, peakTime
+}
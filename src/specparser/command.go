@@ -0,0 +1,92 @@
+package specparser
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Cmd prepares the command of the spec to run through /bin/sh -c, starting it runs the command for real. Stdin is fed
+// to the command's standard input and environment, usually that of the crontab, is added to the environment of the
+// process. A spec with a User runs as that user with the user's supplementary groups, from the user's home directory,
+// with USER and LOGNAME set and HOME set unless environment sets it, as Vixie cron does. Running a command as another
+// user needs root.
+func (s *TaskSpec) Cmd(environment map[string]string) (*exec.Cmd, error) {
+	cmd := exec.Command("/bin/sh", "-c", s.Command)
+	cmd.Stdin = strings.NewReader(s.Stdin)
+	cmd.Env = os.Environ()
+
+	for name, value := range environment {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	if s.User == "" {
+		return cmd, nil
+	}
+
+	credential, home, err := lookupCredential(s.User)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: credential}
+	cmd.Dir = "/"
+
+	if info, err := os.Stat(home); err == nil && info.IsDir() {
+		cmd.Dir = home
+	}
+
+	cmd.Env = append(cmd.Env, "USER="+s.User, "LOGNAME="+s.User)
+
+	if _, ok := environment["HOME"]; !ok {
+		cmd.Env = append(cmd.Env, "HOME="+home)
+	}
+
+	return cmd, nil
+}
+
+// lookupCredential resolves a user name to the uid, gid and supplementary groups a command is run with, and the user's
+// home directory
+func lookupCredential(name string) (*syscall.Credential, string, error) {
+	account, err := user.Lookup(name)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	gid, err := strconv.ParseUint(account.Gid, 10, 32)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	groupIds, err := account.GroupIds()
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	credential := &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+
+	for _, groupId := range groupIds {
+		group, err := strconv.ParseUint(groupId, 10, 32)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		credential.Groups = append(credential.Groups, uint32(group))
+	}
+
+	return credential, account.HomeDir, nil
+}
@@ -0,0 +1,149 @@
+package specparser
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Crontab is the content of a crontab file, the job lines in file order and the NAME=value assignments
+type Crontab struct {
+	Tasks       []TaskSpec
+	Environment map[string]string
+}
+
+// LineError is a failure to parse one line of a crontab file
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// CrontabError collects the failure of every line that could not be parsed
+type CrontabError struct {
+	Errors []LineError
+}
+
+var environmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+func (e LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CrontabError) Error() string {
+	messages := make([]string, len(e.Errors))
+
+	for i := range e.Errors {
+		messages[i] = e.Errors[i].Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e *CrontabError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+
+	for i := range e.Errors {
+		errs[i] = e.Errors[i]
+	}
+
+	return errs
+}
+
+// ParseCrontab reads a crontab file. Blank lines and lines starting with # are skipped, NAME=value lines set the
+// environment and every other line is a job parsed with NewTaskSpecMode. A CRON_TZ line sets the Location of the jobs
+// which follow it, an empty value returns them to local time. Lines which fail do not stop the parse, the valid jobs are
+// returned along with a *CrontabError listing every failure.
+func ParseCrontab(r io.Reader, mode ParseMode) (crontab Crontab, err error) {
+	var crontabError CrontabError
+	var scanner = bufio.NewScanner(r)
+	var lineNumber = 0
+	var location *time.Location
+
+	crontab.Environment = make(map[string]string)
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if match := environmentPattern.FindStringSubmatch(line); match != nil {
+			crontab.Environment[match[1]] = unquote(match[2])
+
+			if match[1] == "CRON_TZ" {
+				if loaded, err := zone(crontab.Environment[match[1]]); err != nil {
+					crontabError.Errors = append(crontabError.Errors, LineError{Line: lineNumber, Err: err})
+				} else {
+					location = loaded
+				}
+			}
+
+			continue
+		}
+
+		taskSpec, err := NewTaskSpecMode(line, mode)
+
+		if err != nil {
+			crontabError.Errors = append(crontabError.Errors, LineError{Line: lineNumber, Err: err})
+			continue
+		}
+
+		taskSpec.Line = lineNumber
+		taskSpec.Location = location
+		crontab.Tasks = append(crontab.Tasks, taskSpec)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return crontab, err
+	}
+
+	if len(crontabError.Errors) > 0 {
+		return crontab, &crontabError
+	}
+
+	return crontab, nil
+}
+
+// zone loads the location named by CRON_TZ, an empty name means local time
+func zone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	return LoadLocation(name)
+}
+
+// unquote removes one pair of matching single or double quotes around an environment value
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// TaskList builds the schedule of every job in the crontab for the window starting at t
+func (c *Crontab) TaskList(t time.Time, lookAheadMins int) (taskList TaskList, err error) {
+	for i := range c.Tasks {
+		list, err := NewTaskList(c.Tasks[i], t, lookAheadMins)
+
+		if err != nil {
+			return taskList, err
+		}
+
+		taskList.Merge(list)
+	}
+
+	return taskList, nil
+}
@@ -0,0 +1,196 @@
+package specparser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DayRuleKind int
+
+const (
+	LastDayOfMonth     DayRuleKind = iota // L or L-n, Offset holds n
+	NearestWeekday                        // nW, Day holds n
+	LastWeekdayOfMonth                    // LW
+	LastDayOfWeek                         // nL, the last given day of week in the month
+	NthDayOfWeek                          // n#k, the k-th given day of week in the month
+)
+
+// DayRule is a day of month or day of week value which depends on the calendar, such as the last day of the month,
+// and so can only be evaluated against a full date
+type DayRule struct {
+	Kind      DayRuleKind
+	Day       Day
+	Offset    int
+	DayOfWeek DayOfWeek
+	Nth       int
+}
+
+var (
+	lastDayPattern        = regexp.MustCompile(`^L(-([0-9]{1,2}))?$`)
+	nearestWeekdayPattern = regexp.MustCompile(`^([0-9]{1,2})W$`)
+	lastWeekdayPattern    = regexp.MustCompile(`^LW$`)
+	lastDayOfWeekPattern  = regexp.MustCompile(`^([0-9]{1,3})L$`)
+	nthDayOfWeekPattern   = regexp.MustCompile(`^([0-9]{1,3})#([0-9])$`)
+)
+
+// daysIn returns the number of days in the month of t
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func isWeekday(weekday time.Weekday) bool {
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+func (r DayRule) Matches(t time.Time) bool {
+	day := t.Day()
+	lastDay := daysIn(t)
+
+	switch r.Kind {
+	case LastDayOfMonth:
+		return day == lastDay-r.Offset
+	case NearestWeekday:
+		target := r.Day.ToInt()
+
+		if target > lastDay {
+			return false
+		}
+
+		return day == nearestWeekday(t, target)
+	case LastWeekdayOfMonth:
+		return day == nearestWeekday(t, lastDay)
+	case LastDayOfWeek:
+		return dayOfWeek(int(t.Weekday())) == r.DayOfWeek && day+7 > lastDay
+	case NthDayOfWeek:
+		return dayOfWeek(int(t.Weekday())) == r.DayOfWeek && (day-1)/7+1 == r.Nth
+	}
+
+	return false
+}
+
+// nearestWeekday finds the monday to friday closest to the target day without leaving the month of t
+func nearestWeekday(t time.Time, target int) int {
+	weekday := time.Date(t.Year(), t.Month(), target, 0, 0, 0, 0, time.UTC).Weekday()
+
+	switch {
+	case isWeekday(weekday):
+		return target
+	case weekday == time.Saturday && target == 1:
+		return target + 2
+	case weekday == time.Saturday:
+		return target - 1
+	case weekday == time.Sunday && target == daysIn(t):
+		return target - 2
+	default:
+		return target + 1
+	}
+}
+
+// expandWithRules expands a day or day of week list where items may also be calendar dependent rules, the rules are
+// returned separately from the plain values
+func (v ValueExpression) expandWithRules(timeUnitType TimeUnitType) (values valueList, rules []DayRule, err error) {
+	var items = strings.Split(v.ToString(), ",")
+	var offset = 0
+
+	for _, item := range items {
+		var rule DayRule
+		var isRule bool
+
+		switch timeUnitType {
+		case TimeUnitDays:
+			rule, isRule, err = parseDayRule(strings.ToUpper(item))
+			break
+		case TimeUnitDaysOfWeek:
+			rule, isRule, err = parseDayOfWeekRule(strings.ToUpper(item))
+			break
+		}
+
+		if err != nil {
+			parseError := newParseError(ReasonInvalidRule, item, offset, err.Error())
+			parseError.Field = timeUnitType.String()
+
+			return values, rules, parseError
+		}
+
+		if isRule {
+			rules = append(rules, rule)
+		} else {
+			itemValues, err := ValueExpression(item).expandValues(timeUnitType)
+
+			if err != nil {
+				return values, rules, asParseError(err, ReasonInvalidValue, item, 0).shift(offset)
+			}
+
+			values = append(values, itemValues...)
+		}
+
+		offset += len(item) + 1
+	}
+
+	return values, rules, nil
+}
+
+func parseDayRule(item string) (rule DayRule, isRule bool, err error) {
+	switch {
+	case lastDayPattern.MatchString(item):
+		rule.Kind = LastDayOfMonth
+
+		if match := lastDayPattern.FindStringSubmatch(item); match[2] != "" {
+			rule.Offset, _ = strconv.Atoi(match[2])
+		}
+
+		if rule.Offset > 30 {
+			return rule, true, errors.New("invalid last day offset")
+		}
+		break
+	case nearestWeekdayPattern.MatchString(item):
+		day, _ := strconv.Atoi(nearestWeekdayPattern.FindStringSubmatch(item)[1])
+		rule = DayRule{Kind: NearestWeekday, Day: Day(day)}
+
+		if day < 1 || day > 31 {
+			return rule, true, errors.New("invalid nearest weekday")
+		}
+		break
+	case lastWeekdayPattern.MatchString(item):
+		rule.Kind = LastWeekdayOfMonth
+		break
+	default:
+		return rule, false, nil
+	}
+
+	return rule, true, nil
+}
+
+func parseDayOfWeekRule(item string) (rule DayRule, isRule bool, err error) {
+	switch {
+	case lastDayOfWeekPattern.MatchString(item):
+		weekday, _ := strconv.Atoi(lastDayOfWeekPattern.FindStringSubmatch(item)[1])
+		rule = DayRule{Kind: LastDayOfWeek, DayOfWeek: dayOfWeek(weekday)}
+
+		if weekday > 7 {
+			return rule, true, errors.New("invalid day of week")
+		}
+		break
+	case nthDayOfWeekPattern.MatchString(item):
+		match := nthDayOfWeekPattern.FindStringSubmatch(item)
+		weekday, _ := strconv.Atoi(match[1])
+		nth, _ := strconv.Atoi(match[2])
+		rule = DayRule{Kind: NthDayOfWeek, DayOfWeek: dayOfWeek(weekday), Nth: nth}
+
+		if weekday > 7 {
+			return rule, true, errors.New("invalid day of week")
+		}
+
+		if nth < 1 || nth > 5 {
+			return rule, true, errors.New("invalid week of month")
+		}
+		break
+	default:
+		return rule, false, nil
+	}
+
+	return rule, true, nil
+}
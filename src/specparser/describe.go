@@ -0,0 +1,347 @@
+package specparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ordinals = []string{"", "first", "second", "third", "fourth", "fifth"}
+
+// Describe returns the schedule in English, such as "At minutes 1 through 15 past every hour from 09:00 to 17:59,
+// Monday through Friday"
+func (s *TaskSpec) Describe() string {
+	if s.Reboot {
+		return "At startup"
+	}
+
+	description := s.Schedule.Describe()
+
+	if s.Location != nil {
+		description += " (" + s.Location.String() + ")"
+	}
+
+	return description
+}
+
+// Describe returns the schedule in English, the time of day first followed by any restriction of the date
+func (t *TimeSpecExtended) Describe() string {
+	parts := []string{describeTime(t)}
+
+	days, daysOfWeek := describeDays(t.Days, t.DayRules), describeDaysOfWeek(t.DaysOfWeek, t.DayOfWeekRules)
+
+	if t.EitherDay() && days != "" && daysOfWeek != "" {
+		if !strings.HasPrefix(daysOfWeek, "on ") {
+			daysOfWeek = "on " + daysOfWeek
+		}
+
+		parts = append(parts, days+" or "+daysOfWeek)
+	} else {
+		if days != "" {
+			parts = append(parts, days)
+		}
+
+		if daysOfWeek != "" {
+			parts = append(parts, daysOfWeek)
+		}
+	}
+
+	if t.Months != fullBits(1, 12) {
+		if step, start, ok := steps(t.Months.Values(), 1, 12); ok {
+			phrase := "every " + strconv.Itoa(step) + " months"
+
+			if start != 1 {
+				phrase += " starting in " + time.Month(start).String()
+			}
+
+			parts = append(parts, phrase)
+		} else {
+			parts = append(parts, "in "+describeValues(t.Months.Values(), func(i int) string { return time.Month(i).String() }))
+		}
+	}
+
+	if !t.AnyYear() {
+		parts = append(parts, "in "+describeValues(t.Years.Values(), strconv.Itoa))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// describeTime covers the seconds, minutes and hours, a single minute within a few hours reads as clock times
+func describeTime(b *TimeSpecExtended) string {
+	seconds := b.Seconds
+
+	if !b.HasSeconds() {
+		seconds = Bits(0).Add(0)
+	}
+
+	if b.Minutes.Count() == 1 && seconds.Count() == 1 && b.Hours.Count() <= 4 {
+		var times []int
+
+		for _, hour := range b.Hours.Values() {
+			times = append(times, hour*3600+b.Minutes.Values()[0]*60+seconds.Values()[0])
+		}
+
+		return "At " + describeList(times, clockTime)
+	}
+
+	phrase := describeUnit(b.Minutes, 0, 59, "minute")
+	everyMinute := b.Minutes == fullBits(0, 59)
+
+	if seconds != Bits(0).Add(0) && everyMinute {
+		phrase = describeUnit(seconds, 0, 59, "second")
+
+		// Seconds alone would read as past the hour
+		if !strings.HasPrefix(phrase, "every") {
+			phrase += " of every minute"
+		}
+	} else if seconds != Bits(0).Add(0) {
+		phrase = describeUnit(seconds, 0, 59, "second") + " of " + phrase
+	}
+
+	if !strings.HasPrefix(phrase, "every") {
+		phrase = "at " + phrase
+	}
+
+	phrase = strings.ToUpper(phrase[:1]) + phrase[1:]
+
+	if b.Hours == fullBits(0, 23) {
+		if everyMinute || strings.HasPrefix(phrase, "Every") {
+			return phrase
+		}
+
+		return phrase + " past every hour"
+	}
+
+	connector := " past every hour from "
+
+	if everyMinute || strings.HasPrefix(phrase, "Every") {
+		connector = " from "
+	}
+
+	if step, start, ok := steps(b.Hours.Values(), 0, 23); ok {
+		if everyMinute {
+			phrase += " of every " + strconv.Itoa(step) + " hours"
+		} else {
+			phrase += " past every " + strconv.Itoa(step) + " hours"
+		}
+
+		if start != 0 {
+			phrase += " starting at " + clockTime(start*3600)
+		}
+
+		return phrase
+	}
+
+	var periods []string
+
+	for _, run := range runs(b.Hours.Values(), 1) {
+		periods = append(periods, clockTime(run[0]*3600)+" to "+clockTime(run[1]*3600+59*60))
+	}
+
+	return phrase + connector + joinPhrases(periods)
+}
+
+// describeUnit names the values of a seconds or minutes field, such as "every 15 minutes" or "minutes 1 through 15"
+func describeUnit(b Bits, min int, max int, unit string) string {
+	values := b.Values()
+
+	if b == fullBits(min, max) {
+		return "every " + unit
+	}
+
+	if step, start, ok := steps(values, min, max); ok {
+		phrase := "every " + strconv.Itoa(step) + " " + unit + "s"
+
+		if start != min {
+			phrase += " starting at " + unit + " " + strconv.Itoa(start)
+		}
+
+		return phrase
+	}
+
+	if len(values) > 1 {
+		unit += "s"
+	}
+
+	return unit + " " + describeValues(values, strconv.Itoa)
+}
+
+func describeDays(days Bits, rules []DayRule) string {
+	var phrases []string
+
+	if days == fullBits(1, 31) {
+		return ""
+	}
+
+	if step, start, ok := steps(days.Values(), 1, 31); ok {
+		phrase := "every " + strconv.Itoa(step) + " days of the month"
+
+		if start != 1 {
+			phrase += " starting on day " + strconv.Itoa(start)
+		}
+
+		phrases = append(phrases, phrase)
+	} else if values := days.Values(); len(values) == 1 {
+		phrases = append(phrases, "on day "+strconv.Itoa(values[0])+" of the month")
+	} else if len(values) > 1 {
+		phrases = append(phrases, "on days "+describeValues(values, strconv.Itoa)+" of the month")
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LastDayOfMonth:
+			if rule.Offset == 0 {
+				phrases = append(phrases, "on the last day of the month")
+			} else {
+				phrases = append(phrases, fmt.Sprintf("on the last day of the month minus %d days", rule.Offset))
+			}
+			break
+		case NearestWeekday:
+			phrases = append(phrases, fmt.Sprintf("on the weekday nearest day %d of the month", rule.Day))
+			break
+		case LastWeekdayOfMonth:
+			phrases = append(phrases, "on the last weekday of the month")
+			break
+		}
+	}
+
+	return joinPhrases(phrases)
+}
+
+func describeDaysOfWeek(daysOfWeek Bits, rules []DayRule) string {
+	var phrases []string
+
+	if daysOfWeek == fullBits(1, 7) {
+		return ""
+	}
+
+	if values := daysOfWeek.Values(); len(values) > 0 {
+		phrases = append(phrases, describeValues(values, weekdayName))
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LastDayOfWeek:
+			phrases = append(phrases, "on the last "+weekdayName(rule.DayOfWeek.ToInt())+" of the month")
+			break
+		case NthDayOfWeek:
+			phrases = append(phrases, "on the "+ordinal(rule.Nth)+" "+weekdayName(rule.DayOfWeek.ToInt())+" of the month")
+			break
+		}
+	}
+
+	return joinPhrases(phrases)
+}
+
+// describeValues lists values with runs of three or more written as "a through b"
+func describeValues(values []int, name func(int) string) string {
+	var phrases []string
+
+	for _, run := range runs(values, 3) {
+		if run[0] == run[1] {
+			phrases = append(phrases, name(run[0]))
+		} else {
+			phrases = append(phrases, name(run[0])+" through "+name(run[1]))
+		}
+	}
+
+	return joinPhrases(phrases)
+}
+
+// describeList lists every value without collapsing runs
+func describeList(values []int, name func(int) string) string {
+	phrases := make([]string, len(values))
+
+	for i := range values {
+		phrases[i] = name(values[i])
+	}
+
+	return joinPhrases(phrases)
+}
+
+// runs groups ascending values into [first, last] pairs of consecutive values, runs shorter than minLength are split
+// back into single values
+func runs(values []int, minLength int) (result [][2]int) {
+	for i := 0; i < len(values); {
+		j := i
+
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+
+		if j-i+1 >= minLength {
+			result = append(result, [2]int{values[i], values[j]})
+		} else {
+			for k := i; k <= j; k++ {
+				result = append(result, [2]int{values[k], values[k]})
+			}
+		}
+
+		i = j + 1
+	}
+
+	return result
+}
+
+// steps recognises values written as */n or m/n, at least three values an equal step apart running to the end of the
+// unit and starting within the first step
+func steps(values []int, min int, max int) (step int, start int, ok bool) {
+	if len(values) < 3 {
+		return 0, 0, false
+	}
+
+	step = values[1] - values[0]
+
+	if step < 2 || values[0]-min >= step || values[len(values)-1]+step <= max {
+		return 0, 0, false
+	}
+
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0, 0, false
+		}
+	}
+
+	return step, values[0], true
+}
+
+// joinPhrases joins phrases as "a, b and c"
+func joinPhrases(phrases []string) string {
+	if len(phrases) < 2 {
+		return strings.Join(phrases, "")
+	}
+
+	return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+}
+
+func fullBits(min int, max int) (b Bits) {
+	for i := min; i <= max; i++ {
+		b = b.Add(i)
+	}
+
+	return b
+}
+
+// clockTime formats seconds since midnight as 15:04, or 15:04:05 when the seconds are not zero
+func clockTime(seconds int) string {
+	if seconds%60 != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/3600, seconds/60%60)
+}
+
+// weekdayName names a day of week numbered 1 to 7 from monday, 0 is also sunday
+func weekdayName(dayOfWeek int) string {
+	return time.Weekday(dayOfWeek % 7).String()
+}
+
+func ordinal(n int) string {
+	if n > 0 && n < len(ordinals) {
+		return ordinals[n]
+	}
+
+	return strconv.Itoa(n) + "th"
+}
@@ -0,0 +1,329 @@
+package specparser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the cron grammar of one scheduler, the fields it reads, how it numbers the days of the week and
+// which extensions it accepts
+type Dialect struct {
+	Name         string
+	Mode         ParseMode // Fields and day of week numbering, with ParseStrictDays when both day fields must match
+	Rules        bool      // L, W and # in the day fields
+	Hash         bool      // Jenkins style H values
+	Macros       bool      // @daily and the other schedule shortcuts
+	Reboot       bool      // @reboot
+	NoSpecific   bool      // One of day of month and day of week has to be ?
+	YearRequired bool      // The year field can not be left out
+	Question     bool      // ? for an unrestricted day field
+	OpenSteps    bool      // Steps from a single value such as 5/10, which run to the end of the field
+}
+
+// ConversionError explains why a schedule has no equivalent in the target dialect
+type ConversionError struct {
+	From    string
+	To      string
+	Field   string // The field which can not be converted, empty when the problem is not within one field
+	Message string
+}
+
+var (
+	DialectVixie      = &Dialect{Name: "vixie", Mode: ParseStandard, Macros: true, Reboot: true}
+	DialectQuartz     = &Dialect{Name: "quartz", Mode: ParseQuartz, Rules: true, NoSpecific: true, Question: true, OpenSteps: true}
+	DialectAWS        = &Dialect{Name: "aws", Mode: ParseYears | ParseQuartzDayOfWeek, Rules: true, NoSpecific: true, YearRequired: true, Question: true, OpenSteps: true}
+	DialectKubernetes = &Dialect{Name: "kubernetes", Mode: ParseStandard, Macros: true, Question: true, OpenSteps: true}
+	DialectJenkins    = &Dialect{Name: "jenkins", Mode: ParseStrictDays, Hash: true, Macros: true}
+)
+
+// Dialects holds the dialects known by name, RegisterDialect adds to it
+var Dialects = map[string]*Dialect{}
+
+// modeDialects holds the dialect of each combination of parse modes, accepting every extension
+var modeDialects [ParseStrictDays << 1]*Dialect
+
+func init() {
+	for _, dialect := range []*Dialect{DialectVixie, DialectQuartz, DialectAWS, DialectKubernetes, DialectJenkins} {
+		RegisterDialect(dialect)
+	}
+
+	for mode := range modeDialects {
+		modeDialects[mode] = newModeDialect(ParseMode(mode))
+	}
+}
+
+func newModeDialect(mode ParseMode) *Dialect {
+	return &Dialect{Mode: mode, Rules: true, Hash: true, Macros: true, Reboot: true, Question: true, OpenSteps: true}
+}
+
+// modeDialect gives the shared dialect of the mode, only a mode with unknown bits set gets one of its own
+func modeDialect(mode ParseMode) *Dialect {
+	if mode >= 0 && int(mode) < len(modeDialects) {
+		return modeDialects[mode]
+	}
+
+	return newModeDialect(mode)
+}
+
+// RegisterDialect makes the dialect available to LookupDialect, a dialect with the same name is replaced
+func RegisterDialect(dialect *Dialect) {
+	Dialects[strings.ToLower(dialect.Name)] = dialect
+}
+
+// LookupDialect finds a registered dialect, names are matched case insensitively
+func LookupDialect(name string) (*Dialect, bool) {
+	dialect, ok := Dialects[strings.ToLower(name)]
+
+	return dialect, ok
+}
+
+func (e *ConversionError) Error() string {
+	message := "can not convert from " + e.From + " to " + e.To
+
+	if e.Field != "" {
+		message += ", " + e.Field + " field"
+	}
+
+	return message + ": " + e.Message
+}
+
+// NewTaskSpecDialect parses a spec written for the dialect, extensions the dialect does not accept are reported as a
+// *ParseError at the field using them
+func NewTaskSpecDialect(spec string, dialect *Dialect) (taskSpec TaskSpec, err error) {
+	if taskSpec, err = NewTaskSpecMode(spec, dialect.Mode); err != nil {
+		return taskSpec, err
+	}
+
+	taskSpec.Dialect = dialect
+
+	return taskSpec, dialect.check(spec, &taskSpec)
+}
+
+// fieldNames lists the time fields of the dialect in order, the year is included when the dialect has one
+func (d *Dialect) fieldNames() []string {
+	names := []string{"minute", "hour", "day", "month", "day-of-week"}
+
+	if d.Mode&ParseSeconds != 0 {
+		names = append([]string{"second"}, names...)
+	}
+
+	if d.Mode&ParseYears != 0 {
+		names = append(names, "year")
+	}
+
+	return names
+}
+
+// check rejects the parts of a parsed spec which the dialect does not accept
+func (d *Dialect) check(spec string, taskSpec *TaskSpec) error {
+	parts, offsets := fields(spec)
+
+	fail := func(reason ParseErrorReason, field string, i int, message string) error {
+		parseError := newParseError(reason, parts[i], offsets[i], message+" in "+d.Name)
+		parseError.Field = field
+
+		return parseError
+	}
+
+	if strings.HasPrefix(taskSpec.Expression, "@") {
+		if taskSpec.Reboot && !d.Reboot || !taskSpec.Reboot && !d.Macros {
+			return fail(ReasonUnknownMacro, "", 0, "macro not supported")
+		}
+
+		return nil
+	}
+
+	names := d.fieldNames()
+	count := len(strings.Fields(taskSpec.Expression))
+	day, dayOfWeek := indexOf(names, "day"), indexOf(names, "day-of-week")
+
+	if d.YearRequired && count < len(names) {
+		return &ParseError{Offset: offsets[count], Reason: ReasonFieldCount, Message: "missing year field, it is required in " + d.Name}
+	}
+
+	for i := 0; i < count; i++ {
+		if expression := ValueExpression(parts[i]); !d.Hash && expression.IsHashed() {
+			return fail(ReasonInvalidValue, names[i], i, "H is not supported")
+		}
+
+		if !d.Question && strings.Contains(parts[i], "?") {
+			return fail(ReasonInvalidValue, names[i], i, "? is not supported")
+		}
+
+		if !d.OpenSteps && hasOpenStep(parts[i]) {
+			return fail(ReasonInvalidStep, names[i], i, "a step needs a range or *")
+		}
+	}
+
+	if !d.Rules && len(taskSpec.Schedule.DayRules) > 0 {
+		return fail(ReasonInvalidRule, names[day], day, "L and W are not supported")
+	}
+
+	if !d.Rules && len(taskSpec.Schedule.DayOfWeekRules) > 0 {
+		return fail(ReasonInvalidRule, names[dayOfWeek], dayOfWeek, "L and # are not supported")
+	}
+
+	if d.NoSpecific && parts[day] != "?" && parts[dayOfWeek] != "?" {
+		return fail(ReasonInvalidValue, names[dayOfWeek], dayOfWeek, "one of day and day of week has to be ?")
+	}
+
+	return nil
+}
+
+// hasOpenStep reports an item stepping from a single value, such as 5/10, rather than from a range, * or H
+func hasOpenStep(field string) bool {
+	for _, item := range strings.Split(field, ",") {
+		if slash := strings.Index(item, "/"); slash > 0 {
+			start := item[:slash]
+
+			if start != "*" && !strings.Contains(start, "-") && !strings.HasPrefix(start, "H") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fromDayOfWeek reads a day of week in the numbering of the dialect, a nil dialect takes both 0 and 7 as sunday
+func (d *Dialect) fromDayOfWeek(i int) DayOfWeek {
+	if d != nil && d.Mode&ParseQuartzDayOfWeek != 0 {
+		return fromQuartzDayOfWeek(i)
+	}
+
+	return dayOfWeek(i)
+}
+
+// Convert rewrites a schedule, the time fields without a command, from one dialect to another. H values are resolved
+// as they are for a job whose command is -. A schedule which can not be written in the target dialect gives a
+// *ConversionError explaining why, one which does not parse gives the *ParseError.
+func Convert(expression string, from *Dialect, to *Dialect) (string, error) {
+	taskSpec, err := NewTaskSpecDialect(expression+" -", from)
+
+	if err != nil {
+		return "", err
+	}
+
+	fail := func(field string, message string) (string, error) {
+		return "", &ConversionError{From: from.Name, To: to.Name, Field: field, Message: message}
+	}
+
+	if taskSpec.Reboot {
+		if !to.Reboot {
+			return fail("", "@reboot is not supported")
+		}
+
+		return MacroReboot, nil
+	}
+
+	schedule := &taskSpec.Schedule
+	var fields []string
+
+	switch {
+	case to.Mode&ParseSeconds != 0 && schedule.HasSeconds():
+		fields = append(fields, renderField(schedule.Seconds.Values(), 0, 59, true))
+		break
+	case to.Mode&ParseSeconds != 0:
+		fields = append(fields, "0")
+		break
+	case schedule.HasSeconds() && schedule.Seconds != Bits(0).Add(0):
+		return fail(TimeUnitSeconds.String(), "there is no seconds field")
+	}
+
+	fields = append(fields, renderField(schedule.Minutes.Values(), 0, 59, schedule.MinuteStar), renderField(schedule.Hours.Values(), 0, 23, schedule.HourStar))
+
+	if (len(schedule.DayRules) > 0 || len(schedule.DayOfWeekRules) > 0) && !to.Rules {
+		return fail("", "L, W and # are not supported")
+	}
+
+	// Work out which day fields restrict the date, and whether a date has to match one or both of them
+	anyDay := schedule.Days == fullBits(1, 31) && len(schedule.DayRules) == 0
+	anyDayOfWeek := schedule.DaysOfWeek == fullBits(1, 7) && len(schedule.DayOfWeekRules) == 0
+	either := schedule.EitherDay()
+
+	if either && (anyDay || anyDayOfWeek) {
+		anyDay, anyDayOfWeek = true, true
+	}
+
+	days, daysOfWeek := "*", "*"
+
+	if !anyDay {
+		days = renderDays(schedule.Days.Values(), schedule.DayRules, schedule.DayStar)
+	}
+
+	if !anyDayOfWeek && to.Mode&ParseQuartzDayOfWeek != 0 {
+		daysOfWeek = renderQuartzDaysOfWeek(schedule.DaysOfWeek.Values(), schedule.DayOfWeekRules)
+	} else if !anyDayOfWeek {
+		daysOfWeek = renderDaysOfWeek(schedule.DaysOfWeek.Values(), schedule.DayOfWeekRules, schedule.DayOfWeekStar)
+	}
+
+	switch {
+	case !anyDay && !anyDayOfWeek && to.NoSpecific:
+		return fail(TimeUnitDaysOfWeek.String(), "both day fields are restricted but one has to be ?")
+	case !anyDay && !anyDayOfWeek && either && to.Mode&ParseStrictDays != 0:
+		return fail(TimeUnitDaysOfWeek.String(), "the schedule runs on days matching either day field but both have to match")
+	case !anyDay && !anyDayOfWeek && !either && to.Mode&ParseStrictDays == 0 && !strings.HasPrefix(days, "*") && !strings.HasPrefix(daysOfWeek, "*"):
+		return fail(TimeUnitDaysOfWeek.String(), "the schedule runs on days matching both day fields but either is enough")
+	case to.NoSpecific && anyDay && !anyDayOfWeek:
+		days = "?"
+		break
+	case to.NoSpecific:
+		daysOfWeek = "?"
+		break
+	}
+
+	fields = append(fields, days, renderField(schedule.Months.Values(), 1, 12, true), daysOfWeek)
+
+	switch {
+	case !schedule.AnyYear() && to.Mode&ParseYears == 0:
+		return fail(TimeUnitYears.String(), "there is no year field")
+	case !schedule.AnyYear():
+		fields = append(fields, renderField(schedule.Years.Values(), YearBitsBase, 2099, false))
+		break
+	case to.YearRequired:
+		fields = append(fields, "*")
+		break
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// renderQuartzDaysOfWeek numbers the days of week from sunday as one
+func renderQuartzDaysOfWeek(daysOfWeek []int, rules []DayRule) string {
+	var items []string
+	var values []int
+
+	for _, value := range daysOfWeek {
+		values = append(values, value%7+1)
+	}
+
+	sort.Ints(values)
+
+	if len(values) > 0 {
+		items = append(items, renderField(values, 1, 7, false))
+	}
+
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LastDayOfWeek:
+			items = append(items, strconv.Itoa(rule.DayOfWeek.ToInt()%7+1)+"L")
+			break
+		case NthDayOfWeek:
+			items = append(items, strconv.Itoa(rule.DayOfWeek.ToInt()%7+1)+"#"+strconv.Itoa(rule.Nth))
+			break
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+func indexOf(names []string, name string) int {
+	for i := range names {
+		if names[i] == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,148 @@
+package specparser
+
+import "time"
+
+// DSTPolicy decides how a schedule behaves when a daylight saving change moves the local clock
+type DSTPolicy int
+
+const (
+	// DSTVixie follows Vixie cron. Fixed time jobs due in a skipped interval run as soon as it ends and run only once
+	// when an interval repeats, jobs with * in the minute or hour field follow the clock as it reads.
+	DSTVixie DSTPolicy = iota
+
+	// DSTLiteral follows the clock as it reads for every job, times in a skipped interval never run and times in a
+	// repeated interval run twice.
+	DSTLiteral
+)
+
+// Larger changes of the local clock are not treated as daylight saving, as in Vixie cron
+const maxTransition = 3 * time.Hour
+
+// isFixedTime reports a spec with neither minute nor hour starting with *, such as 30 2 * * *
+func (s *TaskSpec) isFixedTime() bool {
+	return !s.Schedule.MinuteStar && !s.Schedule.HourStar
+}
+
+// followsClock reports a spec whose fire times are those the clock shows, with no daylight saving rule applied
+func (s *TaskSpec) followsClock() bool {
+	return s.DST == DSTLiteral || !s.isFixedTime()
+}
+
+// firesAt reports whether the spec fires at t, one slot of a schedule stepping by step, under the spec's DST policy
+func (s *TaskSpec) firesAt(t time.Time, step time.Duration) bool {
+	if s.followsClock() {
+		return s.Matches(t)
+	}
+
+	local := s.in(t)
+
+	if repeated(local) {
+		return false
+	}
+
+	return s.wallMismatch(local) == "" || s.skippedMatch(local, step)
+}
+
+// nextSkipped finds the first slot after t and before limit which ends a skipped interval holding a match, t is in the
+// spec's location and a zero limit searches as far as Next does
+func (s *TaskSpec) nextSkipped(t, limit time.Time, step time.Duration) (time.Time, bool) {
+	if limit.IsZero() {
+		limit = t.AddDate(maxSearchYears, 0, 0)
+	}
+
+	for at := t; at.Before(limit); {
+		_, end := at.ZoneBounds()
+
+		if end.IsZero() || !end.Before(limit) {
+			break
+		}
+
+		slot := ceil(end, step)
+
+		if slot.After(t) && slot.Before(limit) && s.skippedMatch(slot, step) {
+			return slot, true
+		}
+
+		at = end
+	}
+
+	return time.Time{}, false
+}
+
+// prevSkipped finds the last slot before t and after limit which ends a skipped interval holding a match, t is in the
+// spec's location and a zero limit searches as far as Prev does
+func (s *TaskSpec) prevSkipped(t, limit time.Time, step time.Duration) (time.Time, bool) {
+	if limit.IsZero() {
+		limit = t.AddDate(-maxSearchYears, 0, 0)
+	}
+
+	for at := t; at.After(limit); {
+		start, _ := at.ZoneBounds()
+
+		if start.IsZero() || !start.After(limit) {
+			break
+		}
+
+		slot := ceil(start, step)
+
+		if slot.Before(t) && slot.After(limit) && s.skippedMatch(slot, step) {
+			return slot, true
+		}
+
+		at = start.Add(-time.Nanosecond)
+	}
+
+	return time.Time{}, false
+}
+
+// ceil rounds t up to a whole step
+func ceil(t time.Time, step time.Duration) time.Time {
+	if rounded := t.Truncate(step); rounded.Before(t) {
+		return rounded.Add(step)
+	}
+
+	return t
+}
+
+// repeated reports a local time which the clock already showed once before falling back
+func repeated(local time.Time) bool {
+	_, offset := local.Zone()
+	_, earlierOffset := local.Add(-maxTransition).Zone()
+
+	if earlierOffset <= offset {
+		return false
+	}
+
+	first := local.Add(-time.Duration(earlierOffset-offset) * time.Second)
+	_, firstOffset := first.Zone()
+
+	return firstOffset == earlierOffset
+}
+
+// skippedMatch reports whether the spec matches a local time the clock jumped over on its way to local, local being the
+// first slot after the jump
+func (s *TaskSpec) skippedMatch(local time.Time, step time.Duration) bool {
+	prev := local.Add(-step)
+	_, offset := local.Zone()
+	_, prevOffset := prev.Zone()
+	gap := time.Duration(offset-prevOffset) * time.Second
+
+	if gap <= 0 || gap > maxTransition {
+		return false
+	}
+
+	wall := wallClock(prev)
+
+	for skipped := step; skipped <= gap; skipped += step {
+		if s.wallMismatch(wall.Add(skipped)) == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wallClock is the time showing the same calendar and clock fields as t in UTC, where no daylight saving applies
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,165 @@
+package specparser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hashPattern matches the Jenkins style H, H(a-b), H/s and H(a-b)/s items
+var hashPattern = regexp.MustCompile(`^H(\(([0-9]{1,4})-([0-9]{1,4})\))?(/([0-9]{1,4}))?$`)
+
+// IsHashed reports an expression with at least one H item
+func (v *ValueExpression) IsHashed() bool {
+	for _, item := range strings.Split(v.ToString(), ",") {
+		if strings.HasPrefix(item, "H") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveHashes replaces every H item with the values it stands for, the fields are hashed separately so a job with H
+// in both minute and hour does not get the same number for each
+func (t TimeExpression) resolveHashes() (resolved TimeExpression, err error) {
+	resolved = t
+	fields := []struct {
+		expression *ValueExpression
+		unit       TimeUnitType
+	}{
+		{&resolved.Second, TimeUnitSeconds},
+		{&resolved.Minute, TimeUnitMinutes},
+		{&resolved.Hour, TimeUnitHours},
+		{&resolved.Day, TimeUnitDays},
+		{&resolved.Month, TimeUnitMonths},
+		{&resolved.DayOfWeek, TimeUnitDaysOfWeek},
+		{&resolved.Year, TimeUnitYears},
+	}
+
+	for _, field := range fields {
+		if *field.expression, err = field.expression.resolveHash(field.unit, t.HashKey, t.QuartzDayOfWeek); err != nil {
+			return t, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// resolveHash picks the value of each H item from the hash of key. H is any value of the field, H(a-b) a value within
+// the range, which may wrap, and H/s every s from one of the first s values. H in the day of month field stays within
+// 1-28 so it fires every month.
+func (v ValueExpression) resolveHash(timeUnitType TimeUnitType, key string, quartz bool) (ValueExpression, error) {
+	if !v.IsHashed() {
+		return v, nil
+	}
+
+	items := strings.Split(v.ToString(), ",")
+	offset := 0
+	hash := hashValue(key, timeUnitType)
+
+	for i, written := range items {
+		if strings.HasPrefix(written, "H") {
+			resolved, err := resolveHashItem(written, timeUnitType, hash, quartz)
+
+			if err != nil {
+				return v, hashError(asParseError(err, ReasonInvalidValue, written, 0).shift(offset), timeUnitType)
+			}
+
+			items[i] = resolved
+		}
+
+		offset += len(written) + 1
+	}
+
+	return ValueExpression(strings.Join(items, ",")), nil
+}
+
+// resolveHashItem resolves a single H item, errors are relative to the item
+func resolveHashItem(written string, timeUnitType TimeUnitType, hash uint32, quartz bool) (string, error) {
+	item := ValueExpression(written).replaceNames(timeUnitType)
+
+	if quartz && timeUnitType == TimeUnitDaysOfWeek {
+		item = ValueExpression(written).substituteNames(QuartzDayOfWeekNames)
+	}
+
+	match := hashPattern.FindStringSubmatch(item.ToString())
+
+	if match == nil {
+		return "", newParseError(ReasonInvalidValue, written, 0, "invalid hash")
+	}
+
+	first, last, err := bounds(timeUnitType)
+
+	if err != nil {
+		return "", err
+	}
+
+	if timeUnitType == TimeUnitDays {
+		last = 28
+	}
+
+	if match[1] != "" {
+		// Names are replaced by values of the same length, so the boundaries can be cut from the written item
+		rangeBoundaries := []string{written[2 : 2+len(match[2])], written[3+len(match[2]) : 3+len(match[2])+len(match[3])]}
+		first, _ = strconv.Atoi(match[2])
+		last, _ = strconv.Atoi(match[3])
+
+		if err = checkRange(first, last, rangeBoundaries, timeUnitType); err != nil {
+			return "", asParseError(err, ReasonInvalidValue, written, 0).shift(2)
+		}
+	}
+
+	values := rangeValues(first, last, timeUnitType)
+
+	if match[4] == "" {
+		return strconv.Itoa(values[hash%uint32(len(values))]), nil
+	}
+
+	step, _ := strconv.Atoi(match[5])
+
+	if step < 1 {
+		return "", newParseError(ReasonInvalidStep, match[5], len(written)-len(match[5]), "invalid step")
+	}
+
+	// A step past the end of the range would leave a single value, which is what plain H is for
+	if step > len(values) {
+		return "", newParseError(ReasonOutOfRange, match[5], len(written)-len(match[5]), fmt.Sprintf("step out of range 1-%d", len(values)))
+	}
+
+	return strconv.Itoa(values[hash%uint32(step)]) + "-" + strconv.Itoa(last) + "/" + strconv.Itoa(step), nil
+}
+
+// hashValue is the FNV-1a hash of the key and the field, it is the same on every host and across restarts
+func hashValue(key string, timeUnitType TimeUnitType) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(key + "\x00" + timeUnitType.String()))
+
+	return hash.Sum32()
+}
+
+func hashError(parseError *ParseError, timeUnitType TimeUnitType) *ParseError {
+	parseError.Field = timeUnitType.String()
+
+	return parseError
+}
+
+// unresolve moves the offset of an error in a resolved expression back to the expression as written. Only items
+// without H fail once resolved, those are unchanged so the offset within the item still holds.
+func (e *ParseError) unresolve(original ValueExpression, resolved ValueExpression) {
+	if original == resolved || e.Offset > len(resolved) {
+		return
+	}
+
+	item := strings.Count(resolved.ToString()[:e.Offset], ",")
+	resolvedItems := strings.Split(resolved.ToString(), ",")
+	originalItems := strings.Split(original.ToString(), ",")
+
+	for i := 0; i < item && i < len(originalItems); i++ {
+		e.Offset += len(originalItems[i]) - len(resolvedItems[i])
+	}
+
+	e.retoken(original.ToString())
+}
@@ -0,0 +1,187 @@
+package specparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type LintSeverity int
+
+const (
+	LintInfo LintSeverity = iota
+	LintWarning
+	LintError
+)
+
+// LintCode identifies the kind of finding, the codes are stable so they can be allowed or denied in review tooling
+type LintCode string
+
+const (
+	LintNeverFires        LintCode = "never-fires"          // No date can match, such as the 30th of February
+	LintUnreachableDay    LintCode = "unreachable-day"      // A day of month which none of the months have
+	LintEveryMinuteInHour LintCode = "every-minute-in-hour" // * in the minute field with a restricted hour
+	LintDayAndWeekday     LintCode = "day-and-weekday"      // Both day fields are restricted so either matching is enough
+	LintUnevenStep        LintCode = "uneven-step"          // A step which does not divide the field, the gap at the wrap differs
+)
+
+// LintFinding is one problem found in a spec, Line is copied from the spec when it was read from a crontab
+type LintFinding struct {
+	Code     LintCode
+	Severity LintSeverity
+	Field    string
+	Message  string
+	Line     int
+}
+
+// The longest each month can be, february is taken as 29 days
+var monthLengths = []int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+
+	return ""
+}
+
+func (f LintFinding) String() string {
+	message := f.Severity.String() + " " + string(f.Code) + ": " + f.Message
+
+	if f.Line > 0 {
+		message = "line " + strconv.Itoa(f.Line) + ": " + message
+	}
+
+	return message
+}
+
+// Lint checks a spec for expressions which are valid but probably not what was meant. A spec which never fires is an
+// error, the other findings are warnings.
+func Lint(taskSpec TaskSpec) (findings []LintFinding) {
+	if taskSpec.Reboot || taskSpec.Timetable != nil {
+		return nil
+	}
+
+	schedule := &taskSpec.Schedule
+
+	add := func(code LintCode, severity LintSeverity, field string, message string) {
+		findings = append(findings, LintFinding{Code: code, Severity: severity, Field: field, Message: message, Line: taskSpec.Line})
+	}
+
+	if schedule.isEmpty() {
+		add(LintNeverFires, LintError, "", "no date matches every field, the spec never fires")
+	} else {
+		longest := 0
+
+		for _, month := range schedule.Months.Values() {
+			if monthLengths[month] > longest {
+				longest = monthLengths[month]
+			}
+		}
+
+		for _, day := range schedule.Days.Values() {
+			if day > longest {
+				add(LintUnreachableDay, LintWarning, TimeUnitDays.String(), fmt.Sprintf("day %d does not occur in any of the months", day))
+			}
+		}
+	}
+
+	if schedule.MinuteStar && schedule.Minutes == fullBits(0, 59) && schedule.Hours != fullBits(0, 23) {
+		add(LintEveryMinuteInHour, LintWarning, TimeUnitMinutes.String(),
+			"* in the minute field runs every minute of each hour, use a single minute such as 0 to run once an hour")
+	}
+
+	if schedule.EitherDay() {
+		add(LintDayAndWeekday, LintWarning, TimeUnitDaysOfWeek.String(),
+			"both day of month and day of week are restricted, the job runs on days matching either, not only both")
+	}
+
+	expressions := taskSpec.fieldExpressions()
+
+	for _, unit := range []TimeUnitType{TimeUnitSeconds, TimeUnitMinutes, TimeUnitHours, TimeUnitDays, TimeUnitMonths, TimeUnitDaysOfWeek} {
+		expression, ok := expressions[unit]
+
+		if !ok {
+			continue
+		}
+
+		for _, item := range strings.Split(expression.ToString(), ",") {
+			if gap, step, ok := wrapGap(ValueExpression(item), unit); ok && gap != step {
+				add(LintUnevenStep, LintWarning, unit.String(),
+					fmt.Sprintf("step %d in %s does not divide the %s range, the gap when it wraps is %d", step, item, unit, gap))
+			}
+		}
+	}
+
+	return findings
+}
+
+// Lint checks every job of the crontab, the findings carry the line numbers of the jobs
+func (c *Crontab) Lint() (findings []LintFinding) {
+	for i := range c.Tasks {
+		findings = append(findings, Lint(c.Tasks[i])...)
+	}
+
+	return findings
+}
+
+// fieldExpressions splits Expression back into its fields, macros give none
+func (s *TaskSpec) fieldExpressions() map[TimeUnitType]ValueExpression {
+	parts := strings.Fields(s.Expression)
+	units := []TimeUnitType{TimeUnitMinutes, TimeUnitHours, TimeUnitDays, TimeUnitMonths, TimeUnitDaysOfWeek}
+
+	if strings.HasPrefix(s.Expression, "@") {
+		return nil
+	}
+
+	if s.Schedule.HasSeconds() {
+		units = append([]TimeUnitType{TimeUnitSeconds}, units...)
+	}
+
+	expressions := make(map[TimeUnitType]ValueExpression)
+
+	for i := range units {
+		if i < len(parts) {
+			expressions[units[i]] = ValueExpression(parts[i])
+		}
+	}
+
+	return expressions
+}
+
+// wrapGap measures the gap between the last value of an open step such as */7 or 5/20 and its first value in the next
+// cycle of the field, ok is false for items which are not open steps
+func wrapGap(item ValueExpression, unit TimeUnitType) (gap int, step int, ok bool) {
+	parts := strings.Split(string(item.replaceNames(unit)), "/")
+
+	if len(parts) != 2 || strings.Contains(parts[0], "-") {
+		return 0, 0, false
+	}
+
+	min, max, err := bounds(unit)
+
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if step, err = strconv.Atoi(parts[1]); err != nil || step < 1 {
+		return 0, 0, false
+	}
+
+	start := min
+
+	if parts[0] != "*" && parts[0] != "?" {
+		if start, err = strconv.Atoi(parts[0]); err != nil || start < min || start > max {
+			return 0, 0, false
+		}
+	}
+
+	last := start + (max-start)/step*step
+
+	return max + 1 - last + start - min, step, true
+}
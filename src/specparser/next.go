@@ -0,0 +1,183 @@
+package specparser
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNeverFires is returned by Next and Prev when no time can match the spec, such as the 30th of February
+var ErrNeverFires = errors.New("spec never fires")
+
+// The gregorian calendar repeats every 400 years, a spec with no match within that span can never match
+const maxSearchYears = 400
+
+// resolution is the smallest step between two fire times of the spec
+func (s *TaskSpec) resolution() time.Duration {
+	if s.Timetable != nil {
+		return s.Timetable.Resolution()
+	}
+
+	if s.Schedule.HasSeconds() {
+		return time.Second
+	}
+
+	return time.Minute
+}
+
+// Next returns the first time after t at which the spec fires, in the location of t. The fields are matched in the spec's
+// Location when it has one and daylight saving changes are handled by the spec's DST policy, so Next agrees with
+// NewTaskList. Rather than stepping through every minute, each field that does not match moves the candidate to the
+// start of the next year, month, day, hour or minute.
+func (s *TaskSpec) Next(t time.Time) (time.Time, error) {
+	if s.Reboot || s.Timetable == nil && s.Schedule.blank() {
+		return time.Time{}, ErrNeverFires
+	}
+
+	result := t.Location()
+	step := s.resolution()
+	t = s.in(t)
+
+	for {
+		found, err := s.nextWall(t)
+
+		if s.followsClock() {
+			return found.In(result), err
+		}
+
+		// A job due in a skipped interval runs on the first slot after it, which may come before found
+		if skipped, ok := s.nextSkipped(t, found, step); ok {
+			return skipped.In(result), nil
+		}
+
+		if err != nil || !repeated(found) {
+			return found.In(result), err
+		}
+
+		t = found
+	}
+}
+
+// nextWall finds the first time after t whose fields match as the clock reads, t is in the spec's location
+func (s *TaskSpec) nextWall(t time.Time) (time.Time, error) {
+	if s.Timetable != nil {
+		return s.Timetable.Next(t)
+	}
+
+	step := s.resolution()
+	limit := t.Year() + maxSearchYears
+	t = t.Truncate(step).Add(step)
+
+	for t.Year() <= limit {
+		var next time.Time
+		var loc = t.Location()
+
+		switch {
+		case !s.HasYear(Year(t.Year())):
+			next = time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+			break
+		case !s.HasMonth(Month(t.Month())):
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			break
+		case !s.MatchDays(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			break
+		case !s.HasHour(Hour(t.Hour())):
+			next = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+			break
+		case !s.HasMinute(Minute(t.Minute())):
+			next = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+			break
+		case s.Schedule.HasSeconds() && !s.HasSecond(Second(t.Second())):
+			next = t.Add(time.Second)
+			break
+		default:
+			return t, nil
+		}
+
+		// Dates inside a daylight saving gap are normalised by time.Date and could land before t
+		if !next.After(t) {
+			next = t.Add(step)
+		}
+
+		t = next
+	}
+
+	return time.Time{}, ErrNeverFires
+}
+
+// Prev returns the last time before t at which the spec fires, in the location of t. Fields are checked in the same
+// order as Next but each mismatch moves the candidate to the end of the previous year, month, day, hour or minute.
+func (s *TaskSpec) Prev(t time.Time) (time.Time, error) {
+	if s.Reboot || s.Timetable == nil && s.Schedule.blank() {
+		return time.Time{}, ErrNeverFires
+	}
+
+	result := t.Location()
+	step := s.resolution()
+	t = s.in(t)
+
+	for {
+		found, err := s.prevWall(t)
+
+		if s.followsClock() {
+			return found.In(result), err
+		}
+
+		if skipped, ok := s.prevSkipped(t, found, step); ok {
+			return skipped.In(result), nil
+		}
+
+		if err != nil || !repeated(found) {
+			return found.In(result), err
+		}
+
+		t = found
+	}
+}
+
+// prevWall finds the last time before t whose fields match as the clock reads, t is in the spec's location
+func (s *TaskSpec) prevWall(t time.Time) (time.Time, error) {
+	if s.Timetable != nil {
+		return s.Timetable.Prev(t)
+	}
+
+	step := s.resolution()
+	limit := t.Year() - maxSearchYears
+	t = t.Add(-1).Truncate(step)
+
+	for t.Year() >= limit {
+		var prev time.Time
+		var loc = t.Location()
+
+		switch {
+		case !s.HasYear(Year(t.Year())):
+			prev = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, loc).Add(-step)
+			break
+		case !s.HasMonth(Month(t.Month())):
+			prev = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-step)
+			break
+		case !s.MatchDays(t):
+			prev = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-step)
+			break
+		case !s.HasHour(Hour(t.Hour())):
+			prev = t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - step)
+			break
+		case !s.HasMinute(Minute(t.Minute())):
+			prev = t.Add(-time.Duration(t.Second())*time.Second - step)
+			break
+		case s.Schedule.HasSeconds() && !s.HasSecond(Second(t.Second())):
+			prev = t.Add(-time.Second)
+			break
+		default:
+			return t, nil
+		}
+
+		if !prev.Before(t) {
+			prev = t.Add(-step)
+		}
+
+		t = prev
+	}
+
+	return time.Time{}, ErrNeverFires
+}
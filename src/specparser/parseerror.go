@@ -0,0 +1,78 @@
+package specparser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseErrorReason is a machine readable code describing why a spec was rejected
+type ParseErrorReason string
+
+const (
+	ReasonFieldCount     ParseErrorReason = "field-count"
+	ReasonMissingCommand ParseErrorReason = "missing-command"
+	ReasonCommandTooLong ParseErrorReason = "command-too-long"
+	ReasonUnknownMacro   ParseErrorReason = "unknown-macro"
+	ReasonInvalidValue   ParseErrorReason = "invalid-value"
+	ReasonInvalidStep    ParseErrorReason = "invalid-step"
+	ReasonOutOfRange     ParseErrorReason = "out-of-range"
+	ReasonReversedRange  ParseErrorReason = "reversed-range"
+	ReasonInvalidRule    ParseErrorReason = "invalid-rule"
+	ReasonUnknownUnit    ParseErrorReason = "unknown-unit"
+)
+
+// ParseError locates a problem within a spec. Offset is the byte offset of Token within the spec passed to
+// NewTaskSpec, or within the expression when returned from ValueExpression.Expand.
+type ParseError struct {
+	Field   string // minute, hour, ... or empty when the problem is not within a time field
+	Offset  int
+	Token   string
+	Reason  ParseErrorReason
+	Message string
+	Err     error // Underlying cause, if any
+}
+
+func newParseError(reason ParseErrorReason, token string, offset int, message string) *ParseError {
+	return &ParseError{Offset: offset, Token: token, Reason: reason, Message: message}
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Message
+
+	if e.Token != "" {
+		msg += " " + strconv.Quote(e.Token)
+	}
+
+	if e.Field != "" {
+		msg += " in " + e.Field + " field"
+	}
+
+	return msg + " at offset " + strconv.Itoa(e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// shift moves the error from a sub expression into the enclosing expression
+func (e *ParseError) shift(offset int) *ParseError {
+	e.Offset += offset
+	return e
+}
+
+// retoken restores the token from the expression the offset refers to, names are replaced before parsing so the
+// token seen by the parser may differ from the one that was written
+func (e *ParseError) retoken(expression string) {
+	if e.Offset >= 0 && e.Offset+len(e.Token) <= len(expression) {
+		e.Token = expression[e.Offset : e.Offset+len(e.Token)]
+	}
+}
+
+// asParseError passes a ParseError through and wraps anything else so callers always get a position
+func asParseError(err error, reason ParseErrorReason, token string, offset int) *ParseError {
+	if parseError, ok := err.(*ParseError); ok {
+		return parseError
+	}
+
+	return &ParseError{Offset: offset, Token: token, Reason: reason, Message: fmt.Sprint(err), Err: err}
+}
@@ -3,6 +3,7 @@ package specparser
 import (
 	"io/ioutil"
 	"log"
+	"sort"
 	"time"
 )
 
@@ -13,8 +14,8 @@ var Debug = log.New(ioutil.Discard, "", 0)
 //var DevelOut *log.Logger = log.New(os.Stdout,"", 0)
 
 type (
-	Work     map[time.Time]*TaskSpec // Time indexed pointers to TaskSpec, TaskSpec.Command can then be accessed
-	Schedule []time.Time             // Ordered slice of Work indexes, a queue interface would be nice (push, pop - ensure ptr's are cleared)
+	Work     map[time.Time][]*TaskSpec // Time indexed pointers to every TaskSpec due at that time
+	Schedule []time.Time               // Ordered slice of Work indexes, a queue interface would be nice (push, pop - ensure ptr's are cleared)
 	TaskList struct {
 		Work     Work
 		Schedule Schedule
@@ -22,7 +23,7 @@ type (
 )
 
 func NewWork() Work {
-	return make(map[time.Time]*TaskSpec)
+	return make(map[time.Time][]*TaskSpec)
 }
 
 func NewSchedule() Schedule {
@@ -38,8 +39,28 @@ func (t *TaskList) AddTask(time time.Time, spec *TaskSpec) *TaskList {
 		t.Work = NewWork()
 	}
 
-	t.Work[time] = spec
-	t.Schedule = append(t.Schedule, time)
+	// Monotonic clock readings would make equal times distinct map keys
+	time = time.Round(0)
+
+	if _, ok := t.Work[time]; !ok {
+		i := sort.Search(len(t.Schedule), func(i int) bool { return !t.Schedule[i].Before(time) })
+		t.Schedule = append(t.Schedule, time)
+		copy(t.Schedule[i+1:], t.Schedule[i:])
+		t.Schedule[i] = time
+	}
+
+	t.Work[time] = append(t.Work[time], spec)
+	return t
+}
+
+// Merge adds every task of another list, the schedule stays in time order
+func (t *TaskList) Merge(other TaskList) *TaskList {
+	for _, time := range other.Schedule {
+		for _, spec := range other.Work[time] {
+			t.AddTask(time, spec)
+		}
+	}
+
 	return t
 }
 
@@ -51,33 +72,28 @@ func NewTaskList(spec TaskSpec, t time.Time, lookAheadMins int) (taskList TaskLi
 	Debug.Printf(debugMsg, lookAheadMins, t, t.Add(time.Minute*time.Duration(10)))
 
 	var failMsg string
+	var step, slots = time.Minute, lookAheadMins
 
-	for i := 0; i < lookAheadMins; i++ {
-		var pass = false
+	// Specs with a seconds field are checked at every second of the window
+	if spec.resolution() == time.Second {
+		step, slots = time.Second, lookAheadMins*60
+		t = t.Truncate(time.Second)
+	}
+
+	for i := 0; i < slots; i++ {
+		var second = Second(t.Second())
 		var minute = Minute(t.Minute())
 		var hour = Hour(t.Hour())
 		var day = Day(t.Day())
 		var month = Month(t.Month())
 		var dayOfWeek = DayOfWeek(t.Weekday())
+		var year = Year(t.Year())
+
+		failMsg = spec.mismatch(t)
+		pass := spec.firesAt(t, step)
 
-		switch {
-		case !spec.HasMonth(month):
-			failMsg = "not in month"
-			break
-		case !spec.HasDayOfWeek(dayOfWeek):
-			failMsg = "not in daysOfWeek"
-			break
-		case !spec.HasDay(day):
-			failMsg = "not in days"
-			break
-		case !spec.HasHour(hour):
-			failMsg = "not in hours"
-			break
-		case !spec.HasMinute(minute):
-			failMsg = "not in minutes"
-			break
-		default:
-			pass = true
+		if !pass && failMsg == "" {
+			failMsg = "repeated by daylight saving"
 		}
 
 		Debug.Println("Pass: ", pass)
@@ -85,15 +101,15 @@ func NewTaskList(spec TaskSpec, t time.Time, lookAheadMins int) (taskList TaskLi
 		if pass {
 			taskList.AddTask(t, &spec)
 			Debug.Println(spec.Expression, "matches")
-			Debug.Printf("%4d-%02d-%02d %02d:%02d:00 +0000 (Day:%d)\n", 2017, month, day, hour, minute, dayOfWeek)
+			Debug.Printf("%4d-%02d-%02d %02d:%02d:%02d +0000 (Day:%d)\n", year, month, day, hour, minute, second, dayOfWeek)
 		} else {
 			Debug.Println(failMsg)
-			Debug.Printf("%4d-%02d-%02d %02d:%02d:00 +0000 (Day:%d)\n", 2017, month, day, hour, minute, dayOfWeek)
+			Debug.Printf("%4d-%02d-%02d %02d:%02d:%02d +0000 (Day:%d)\n", year, month, day, hour, minute, second, dayOfWeek)
 		}
 
 		Debug.Println()
 
-		t = t.Add(time.Minute)
+		t = t.Add(step)
 	}
 
 	return taskList, err
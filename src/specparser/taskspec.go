@@ -1,82 +1,341 @@
 package specparser
 
 import (
-	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const CommandMaxStringLength = 999
 
+const MacroReboot = "@reboot"
+
+// Macros maps the predefined schedule shortcuts onto their five field equivalent, @reboot has no time fields and is
+// handled separately.
+var Macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseMode selects optional parts of the spec grammar, modes may be combined
+type ParseMode int
+
+const ParseStandard ParseMode = 0
+
+const (
+	ParseSeconds         ParseMode = 1 << iota // A leading seconds field precedes the minutes
+	ParseYears                                 // An optional year field may follow the day of week
+	ParseQuartzDayOfWeek                       // Day of week is numbered 1-7 starting from sunday
+	ParseSystem                                // A user field follows the time fields, as in /etc/crontab and /etc/cron.d
+	ParseStrictDays                            // Day of month and day of week must both match when both are restricted
+)
+
+// ParseQuartz reads the Quartz layout, seconds minutes hours day month day-of-week [year]
+const ParseQuartz = ParseSeconds | ParseYears | ParseQuartzDayOfWeek
+
+var yearPattern = regexp.MustCompile(`^(\*|[0-9]{4})[0-9,/-]*$`)
+
 type TaskSpec struct {
 	Expression string
 	Schedule   TimeSpecExtended
 	Command    string
+	Stdin      string         // Text after the first unescaped % of the command, each further % becomes a newline
+	User       string         // The user to run the command as, only set by ParseSystem
+	Location   *time.Location // Time zone the fields are read in, nil uses the location of the time being checked
+	Timetable  Timetable      // Fires on this rather than Schedule when set, such as a CompositeSchedule
+	Reboot     bool           // Run once when the process starts rather than on a schedule
+	Line       int            // Line number within the crontab file, zero when not read from a file
+	Dialect    *Dialect       // The dialect the spec was written in, nil when built by hand
+	DST        DSTPolicy      // How daylight saving changes are handled by NewTaskList, Next and Prev
 }
 
-func NewTaskSpec(spec string) (taskSpec TaskSpec, err error) {
-	parts := strings.Fields(strings.Trim(spec, " "))
+// NewTaskSpec parses a spec in the given dialect, without one the standard fields are read with every extension
+// accepted
+func NewTaskSpec(spec string, dialect ...*Dialect) (taskSpec TaskSpec, err error) {
+	if len(dialect) > 0 && dialect[0] != nil {
+		return NewTaskSpecDialect(spec, dialect[0])
+	}
 
-	if len(parts) < 6 {
-		err = errors.New(fmt.Sprintf("%s %d", "invalid spec only has", len(parts)))
+	return NewTaskSpecMode(spec, ParseStandard)
+}
+
+// NewTaskSpecMode parses a spec using the grammar selected by mode, failures are reported as a *ParseError with offsets
+// into spec. The spec's Dialect is that of the mode, accepting every extension, so days of week read back as written.
+func NewTaskSpecMode(spec string, mode ParseMode) (taskSpec TaskSpec, err error) {
+	taskSpec, err = parseTaskSpec(spec, mode)
+	taskSpec.Dialect = modeDialect(mode)
+
+	return taskSpec, err
+}
+
+func parseTaskSpec(spec string, mode ParseMode) (taskSpec TaskSpec, err error) {
+	parts, offsets := fields(spec)
+
+	if len(parts) > 0 && strings.HasPrefix(parts[0], "@") {
+		return newMacroTaskSpec(spec, parts, offsets, mode)
+	}
+
+	var timeExpression *TimeExpression
+	var fieldNames = []string{"minute", "hour", "day", "month", "day-of-week"}
+
+	if mode&ParseSeconds != 0 {
+		fieldNames = append([]string{"second"}, fieldNames...)
+	}
+
+	var fieldCount = len(fieldNames)
+	var trailing = 1 // The command, and the user in system mode
+
+	if mode&ParseSystem != 0 {
+		trailing++
+	}
+
+	if len(parts) < fieldCount+trailing {
+		err = newParseError(ReasonFieldCount, "", len(spec), fmt.Sprintf("invalid spec only has %d of %d fields", len(parts), fieldCount+trailing))
 		return
 	}
 
-	extendedTimeSpec, err := TimeExpression{}.New(parts[0], parts[1], parts[2], parts[3], parts[4]).Explode()
-	//extendedTimeSpec, err := TimeExpression{
-	//		Minute:    ValueExpression(parts[0]),
-	//		Hour:      ValueExpression(parts[1]),
-	//		Day:       ValueExpression(parts[2]),
-	//		Month:     ValueExpression(parts[3]),
-	//		DayOfWeek: ValueExpression(parts[4]),
-	//	}.Explode()
+	if mode&ParseSeconds != 0 {
+		timeExpression = TimeExpression{}.NewWithSeconds(parts[0], parts[1], parts[2], parts[3], parts[4], parts[5])
+	} else {
+		timeExpression = TimeExpression{}.New(parts[0], parts[1], parts[2], parts[3], parts[4])
+	}
+
+	// The year is optional, it is only taken when a command still follows it
+	if mode&ParseYears != 0 && len(parts) > fieldCount+trailing && yearPattern.MatchString(parts[fieldCount]) {
+		timeExpression.Year = ValueExpression(parts[fieldCount])
+		fieldNames = append(fieldNames, "year")
+		fieldCount++
+	}
+
+	command := fieldCount + trailing - 1
+	taskSpec.Command, taskSpec.Stdin = splitPercent(commandText(spec, offsets[command]))
+
+	if mode&ParseSystem != 0 {
+		taskSpec.User = parts[fieldCount]
+	}
+
+	// The same command run by two users of a system crontab is two jobs, which should not share their H values
+	timeExpression.QuartzDayOfWeek = mode&ParseQuartzDayOfWeek != 0
+	timeExpression.HashKey = taskSpec.Command
+
+	if taskSpec.User != "" {
+		timeExpression.HashKey = taskSpec.User + " " + taskSpec.Command
+	}
 
-	taskSpec = TaskSpec{
-		Expression: strings.Join(parts[0:5], " "),
-		Schedule:   extendedTimeSpec,
-		Command:    strings.Join(parts[5:], " "),
+	extendedTimeSpec, err := timeExpression.Explode()
+	extendedTimeSpec.StrictDays = mode&ParseStrictDays != 0
+
+	if parseError, ok := err.(*ParseError); ok {
+		for i := range fieldNames {
+			if fieldNames[i] == parseError.Field {
+				parseError.shift(offsets[i])
+				break
+			}
+		}
 	}
 
-	if len(taskSpec.Command) > CommandMaxStringLength {
-		err = errors.New("command exceeds maximum length of " + string(CommandMaxStringLength) + " chars")
+	taskSpec.Expression = strings.Join(parts[0:fieldCount], " ")
+	taskSpec.Schedule = extendedTimeSpec
+
+	if err == nil {
+		err = taskSpec.checkCommand(offsets[command])
 	}
 
 	return taskSpec, err
 }
 
-func (s *TaskSpec) HasMinute(minute Minute) bool {
-	for i := range s.Schedule.Minutes {
-		if s.Schedule.Minutes[i] == minute {
-			return true
+func newMacroTaskSpec(spec string, parts []string, offsets []int, mode ParseMode) (taskSpec TaskSpec, err error) {
+	macro := strings.ToLower(parts[0])
+	command := 1
+
+	if mode&ParseSystem != 0 {
+		command++
+	}
+
+	if len(parts) < command+1 {
+		err = &ParseError{Field: "command", Offset: len(spec), Reason: ReasonMissingCommand, Message: "missing command"}
+		return
+	}
+
+	taskSpec = TaskSpec{Expression: macro}
+	taskSpec.Command, taskSpec.Stdin = splitPercent(commandText(spec, offsets[command]))
+
+	if mode&ParseSystem != 0 {
+		taskSpec.User = parts[1]
+	}
+
+	if macro == MacroReboot {
+		taskSpec.Reboot = true
+	} else if expression, ok := Macros[macro]; ok {
+		fields := strings.Fields(expression)
+		taskSpec.Schedule, err = TimeExpression{}.New(fields[0], fields[1], fields[2], fields[3], fields[4]).Explode()
+	} else {
+		err = newParseError(ReasonUnknownMacro, parts[0], offsets[0], "unknown macro")
+	}
+
+	if err == nil {
+		err = taskSpec.checkCommand(offsets[command])
+	}
+
+	return taskSpec, err
+}
+
+func (s *TaskSpec) checkCommand(offset int) error {
+	if len(s.Command) > CommandMaxStringLength {
+		message := "command exceeds maximum length of " + strconv.Itoa(CommandMaxStringLength) + " chars"
+		return &ParseError{Field: "command", Offset: offset, Reason: ReasonCommandTooLong, Message: message}
+	}
+
+	return nil
+}
+
+// commandText is the rest of spec from offset, whitespace inside the command is kept as written
+func commandText(spec string, offset int) string {
+	return strings.TrimRightFunc(spec[offset:], unicode.IsSpace)
+}
+
+// splitPercent applies the cron rules for % in a command. The command ends at the first unescaped %, the remaining text
+// is the input with each unescaped % turned into a newline and a final newline added when missing. \% is a literal % in either part.
+func splitPercent(raw string) (command string, stdin string) {
+	var parts = []*strings.Builder{new(strings.Builder), new(strings.Builder)}
+	var part = 0
+
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case raw[i] == '\\' && i+1 < len(raw) && raw[i+1] == '%':
+			parts[part].WriteByte('%')
+			i++
+			break
+		case raw[i] == '%' && part == 0:
+			part = 1
+			break
+		case raw[i] == '%':
+			parts[part].WriteByte('\n')
+			break
+		default:
+			parts[part].WriteByte(raw[i])
 		}
 	}
 
-	return false
+	if part == 1 {
+		stdin = parts[1].String()
+
+		if stdin != "" && !strings.HasSuffix(stdin, "\n") {
+			stdin += "\n"
+		}
+	}
+
+	return parts[0].String(), stdin
 }
 
-func (s *TaskSpec) HasHour(hour Hour) bool {
-	for i := range s.Schedule.Hours {
-		if s.Schedule.Hours[i] == hour {
-			return true
+// fields splits spec around whitespace as strings.Fields does, the byte offset of each field is also returned
+func fields(spec string) (parts []string, offsets []int) {
+	start := -1
+
+	for i, r := range spec {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				parts = append(parts, spec[start:i])
+				offsets = append(offsets, start)
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
 		}
 	}
 
-	return false
+	if start >= 0 {
+		parts = append(parts, spec[start:])
+		offsets = append(offsets, start)
+	}
+
+	return parts, offsets
 }
 
-func (s *TaskSpec) HasDay(day Day) bool {
-	for i := range s.Schedule.Days {
-		if s.Schedule.Days[i] == day {
-			return true
+// Matches reports whether the spec fires at t, seconds are only compared when the spec has a seconds field
+func (s *TaskSpec) Matches(t time.Time) bool {
+	return s.mismatch(t) == ""
+}
+
+// mismatch describes the first field of t which the spec does not match, the result is empty when t matches
+func (s *TaskSpec) mismatch(t time.Time) string {
+	return s.wallMismatch(s.in(t))
+}
+
+// wallMismatch compares the fields of t as they read, without moving t into the spec's location
+func (s *TaskSpec) wallMismatch(t time.Time) string {
+	if s.Timetable != nil {
+		if !s.Timetable.Matches(t) {
+			return "not in timetable"
 		}
+
+		return ""
 	}
 
-	return false
+	switch {
+	case !s.HasYear(Year(t.Year())):
+		return "not in years"
+	case !s.HasMonth(Month(t.Month())):
+		return "not in month"
+	case !s.MatchDays(t):
+		return "not in days"
+	case !s.HasHour(Hour(t.Hour())):
+		return "not in hours"
+	case !s.HasMinute(Minute(t.Minute())):
+		return "not in minutes"
+	case s.Schedule.HasSeconds() && !s.HasSecond(Second(t.Second())):
+		return "not in seconds"
+	}
+
+	return ""
+}
+
+func (s *TaskSpec) HasSecond(second Second) bool {
+	return s.Schedule.Seconds.Has(int(second))
+}
+
+func (s *TaskSpec) HasMinute(minute Minute) bool {
+	return s.Schedule.Minutes.Has(int(minute))
+}
+
+func (s *TaskSpec) HasHour(hour Hour) bool {
+	return s.Schedule.Hours.Has(int(hour))
+}
+
+func (s *TaskSpec) HasDay(day Day) bool {
+	return s.Schedule.Days.Has(int(day))
 }
 
 func (s *TaskSpec) HasMonth(month Month) bool {
-	for i := range s.Schedule.Months {
-		if s.Schedule.Months[i] == month {
+	return s.Schedule.Months.Has(int(month))
+}
+
+// MatchDays applies both day fields as cron does, when both are restricted a day matching either is enough unless the
+// schedule has StrictDays set
+func (s *TaskSpec) MatchDays(t time.Time) bool {
+	if s.Schedule.EitherDay() {
+		return s.MatchDay(t) || s.MatchDayOfWeek(t)
+	}
+
+	return s.MatchDay(t) && s.MatchDayOfWeek(t)
+}
+
+// MatchDay checks the day of month of t against both the day values and the calendar dependent day rules
+func (s *TaskSpec) MatchDay(t time.Time) bool {
+	if s.HasDay(Day(t.Day())) {
+		return true
+	}
+
+	for i := range s.Schedule.DayRules {
+		if s.Schedule.DayRules[i].Matches(t) {
 			return true
 		}
 	}
@@ -84,17 +343,27 @@ func (s *TaskSpec) HasMonth(month Month) bool {
 	return false
 }
 
-func (s *TaskSpec) HasDayOfWeek(dayOfWeek DayOfWeek) bool {
-	// Handle zero as sunday
-	if dayOfWeek == 0 {
-		dayOfWeek = 7
+// MatchDayOfWeek checks the weekday of t against both the day of week values and the calendar dependent rules
+func (s *TaskSpec) MatchDayOfWeek(t time.Time) bool {
+	if s.Schedule.DaysOfWeek.Has(dayOfWeek(int(t.Weekday())).ToInt()) {
+		return true
 	}
 
-	for i := range s.Schedule.DaysOfWeek {
-		if s.Schedule.DaysOfWeek[i] == dayOfWeek {
+	for i := range s.Schedule.DayOfWeekRules {
+		if s.Schedule.DayOfWeekRules[i].Matches(t) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// HasYear matches any year when the spec has no year field
+func (s *TaskSpec) HasYear(year Year) bool {
+	return s.Schedule.AnyYear() || s.Schedule.Years.Has(int(year))
+}
+
+// HasDayOfWeek checks a day of week numbered as in the dialect of the spec, without one zero and seven are both sunday
+func (s *TaskSpec) HasDayOfWeek(dayOfWeek DayOfWeek) bool {
+	return s.Schedule.DaysOfWeek.Has(s.Dialect.fromDayOfWeek(int(dayOfWeek)).ToInt())
+}
@@ -1,31 +1,60 @@
 package specparser
 
+import (
+	"regexp"
+	"strconv"
+)
+
 type TimeUnit interface {
 	ToInt() int
 }
 
 type TimeExpression struct {
+	Second    ValueExpression // Optional, empty when the expression has minute resolution
 	Minute    ValueExpression
 	Hour      ValueExpression
 	Day       ValueExpression
 	Month     ValueExpression
 	DayOfWeek ValueExpression
+	Year      ValueExpression // Optional, empty or a wildcard matches any year
+
+	QuartzDayOfWeek bool   // Day of week is numbered 1-7 starting from sunday
+	HashKey         string // Identifies the job, H values are derived from its hash
 }
 
+// TimeSpecExtended holds each field as a bitmask of the values it matches, see Bits. A schedule assembled by hand
+// with NewBits has minute resolution and matches any year while Seconds and Years are left empty, NewTimeSpecExtended
+// also sets the star flags which decide how the day fields combine.
 type TimeSpecExtended struct {
-	Minutes    []TimeUnit
-	Hours      []TimeUnit
-	Days       []TimeUnit
-	Months     []TimeUnit
-	DaysOfWeek []TimeUnit
+	Seconds    Bits // Empty when the expression has minute resolution
+	Minutes    Bits
+	Hours      Bits
+	Days       Bits
+	Months     Bits
+	DaysOfWeek Bits     // Sunday is stored as 7
+	Years      YearBits // Empty when any year matches
+
+	DayRules       []DayRule // Calendar dependent day values, L, L-n, nW and LW
+	DayOfWeekRules []DayRule // Calendar dependent day of week values, nL and n#k
+
+	// Fields written starting with * or ?, cron treats these as unrestricted even when a step follows
+	MinuteStar    bool
+	HourStar      bool
+	DayStar       bool
+	DayOfWeekStar bool
+
+	// Day of month and day of week must both match, otherwise either is enough when neither is starred
+	StrictDays bool
 }
 
 type (
+	Second    int
 	Minute    int
 	Hour      int
 	Day       int
 	Month     int
 	DayOfWeek int
+	Year      int
 )
 
 func (t TimeExpression) New(minute string, hour string, day string, month string, dayOfWeek string) *TimeExpression {
@@ -38,6 +67,16 @@ func (t TimeExpression) New(minute string, hour string, day string, month string
 	return &t
 }
 
+func (t TimeExpression) NewWithSeconds(second string, minute string, hour string, day string, month string, dayOfWeek string) *TimeExpression {
+	t.Second = ValueExpression(second)
+
+	return t.New(minute, hour, day, month, dayOfWeek)
+}
+
+func (m Second) ToInt() int {
+	return int(m)
+}
+
 func (m Minute) ToInt() int {
 	return int(m)
 }
@@ -58,26 +97,188 @@ func (m DayOfWeek) ToInt() int {
 	return int(m)
 }
 
+func (m Year) ToInt() int {
+	return int(m)
+}
+
+// Explode expands every field to the values it matches, H items are first resolved from HashKey
 func (t TimeExpression) Explode() (timeSpecExtended TimeSpecExtended, err error) {
-	if timeSpecExtended.Minutes, err = t.Minute.Expand(TimeUnitMinutes); err != nil {
+	resolved, err := t.resolveHashes()
+
+	if err != nil {
+		return timeSpecExtended, err
+	}
+
+	timeSpecExtended, err = resolved.explode()
+
+	if parseError, ok := err.(*ParseError); ok {
+		parseError.unresolve(t.field(parseError.Field), resolved.field(parseError.Field))
+	}
+
+	return timeSpecExtended, err
+}
+
+// field returns the expression of the named field
+func (t TimeExpression) field(name string) ValueExpression {
+	switch name {
+	case TimeUnitSeconds.String():
+		return t.Second
+	case TimeUnitMinutes.String():
+		return t.Minute
+	case TimeUnitHours.String():
+		return t.Hour
+	case TimeUnitDays.String():
+		return t.Day
+	case TimeUnitMonths.String():
+		return t.Month
+	case TimeUnitDaysOfWeek.String():
+		return t.DayOfWeek
+	case TimeUnitYears.String():
+		return t.Year
+	}
+
+	return ""
+}
+
+func (t TimeExpression) explode() (timeSpecExtended TimeSpecExtended, err error) {
+	if t.Second != "" {
+		if timeSpecExtended.Seconds, err = t.Second.expandBits(TimeUnitSeconds); err != nil {
+			return timeSpecExtended, err
+		}
+	}
+
+	if timeSpecExtended.Minutes, err = t.Minute.expandBits(TimeUnitMinutes); err != nil {
 		return timeSpecExtended, err
 	}
 
-	if timeSpecExtended.Hours, err = t.Hour.Expand(TimeUnitHours); err != nil {
+	if timeSpecExtended.Hours, err = t.Hour.expandBits(TimeUnitHours); err != nil {
 		return timeSpecExtended, err
 	}
 
-	if timeSpecExtended.Days, err = t.Day.Expand(TimeUnitDays); err != nil {
+	days, dayRules, err := t.Day.expandWithRules(TimeUnitDays)
+
+	if err != nil {
 		return timeSpecExtended, err
 	}
 
-	if timeSpecExtended.Months, err = t.Month.Expand(TimeUnitMonths); err != nil {
+	timeSpecExtended.Days, timeSpecExtended.DayRules = bitsOf(days), dayRules
+
+	if timeSpecExtended.Months, err = t.Month.expandBits(TimeUnitMonths); err != nil {
 		return timeSpecExtended, err
 	}
 
-	if timeSpecExtended.DaysOfWeek, err = t.DayOfWeek.Expand(TimeUnitDaysOfWeek); err != nil {
+	dayOfWeekExpression := t.DayOfWeek.replaceNames(TimeUnitDaysOfWeek)
+	var starSteps [][]int
+
+	if t.QuartzDayOfWeek {
+		dayOfWeekExpression = t.DayOfWeek.substituteNames(QuartzDayOfWeekNames)
+
+		if err = dayOfWeekExpression.checkQuartzDayOfWeek(); err != nil {
+			return timeSpecExtended, err
+		}
+
+		// Quartz numbers sunday as one, a step from * runs over 1-7 rather than from a sunday numbered zero
+		starSteps = quartzStarStep.FindAllStringIndex(dayOfWeekExpression.ToString(), -1)
+		dayOfWeekExpression = ValueExpression(quartzStarStep.ReplaceAllString(dayOfWeekExpression.ToString(), "${1}1-7/"))
+	}
+
+	daysOfWeek, dayOfWeekRules, err := dayOfWeekExpression.expandWithRules(TimeUnitDaysOfWeek)
+
+	if err != nil {
+		if parseError, ok := err.(*ParseError); ok {
+			// Each */ widened to 1-7/ moved what follows it two places on
+			for i, step := range starSteps {
+				if parseError.Offset >= step[1]+2*(i+1) {
+					parseError.Offset -= 2
+				}
+			}
+
+			parseError.retoken(t.DayOfWeek.ToString())
+		}
+
 		return timeSpecExtended, err
 	}
 
+	if t.QuartzDayOfWeek {
+		for i := range daysOfWeek {
+			daysOfWeek[i] = fromQuartzDayOfWeek(daysOfWeek[i]).ToInt()
+		}
+
+		for i := range dayOfWeekRules {
+			dayOfWeekRules[i].DayOfWeek = fromQuartzDayOfWeek(dayOfWeekRules[i].DayOfWeek.ToInt())
+		}
+	}
+
+	timeSpecExtended.DaysOfWeek, timeSpecExtended.DayOfWeekRules = bitsOf(daysOfWeek), dayOfWeekRules
+
+	if t.Year != "" && !t.Year.IsWildCard() {
+		if timeSpecExtended.Years, err = t.Year.expandYearBits(); err != nil {
+			return timeSpecExtended, err
+		}
+	}
+
+	timeSpecExtended.MinuteStar = t.Minute.isStarred()
+	timeSpecExtended.HourStar = t.Hour.isStarred()
+	timeSpecExtended.DayStar = t.Day.isStarred()
+	timeSpecExtended.DayOfWeekStar = t.DayOfWeek.isStarred()
+
 	return timeSpecExtended, err
 }
+
+// NewTimeSpecExtended assembles a schedule from the values of each field, a field holding every value is marked as
+// written with * so the day fields combine as they would when read by cron
+func NewTimeSpecExtended(minutes, hours, days, months, daysOfWeek []TimeUnit) TimeSpecExtended {
+	t := TimeSpecExtended{
+		Minutes:    NewBits(minutes),
+		Hours:      NewBits(hours),
+		Days:       NewBits(days),
+		Months:     NewBits(months),
+		DaysOfWeek: NewBits(daysOfWeek),
+	}
+
+	t.MinuteStar = t.Minutes == fullBits(0, 59)
+	t.HourStar = t.Hours == fullBits(0, 23)
+	t.DayStar = t.Days == fullBits(1, 31)
+	t.DayOfWeekStar = t.DaysOfWeek == fullBits(1, 7)
+
+	return t
+}
+
+// EitherDay reports whether a date matching only one of the day of month and day of week fields fires, as it does in
+// cron when neither field was written starting with *. A field such as 1-31 holds every day but is still restricted.
+func (t *TimeSpecExtended) EitherDay() bool {
+	return !t.StrictDays && !t.DayStar && !t.DayOfWeekStar
+}
+
+var quartzStarStep = regexp.MustCompile(`(^|,)\*/`)
+
+// fromQuartzDayOfWeek converts a Quartz day of week where sunday is one to the 1-7 monday first numbering
+func fromQuartzDayOfWeek(i int) DayOfWeek {
+	if i == 1 {
+		return DayOfWeek(7)
+	}
+
+	return DayOfWeek(i - 1)
+}
+
+// checkQuartzDayOfWeek rejects zero as a day of week, Quartz numbers the days 1-7 so zero is not another sunday
+func (v ValueExpression) checkQuartzDayOfWeek() error {
+	expression := v.ToString()
+
+	for _, location := range regexp.MustCompile(`[0-9]+`).FindAllStringIndex(expression, -1) {
+		start, end := location[0], location[1]
+
+		if start > 0 && (expression[start-1] == '/' || expression[start-1] == '#') {
+			continue
+		}
+
+		if value, _ := strconv.Atoi(expression[start:end]); value == 0 {
+			parseError := newParseError(ReasonOutOfRange, expression[start:end], start, "value out of range 1-7")
+			parseError.Field = TimeUnitDaysOfWeek.String()
+
+			return parseError
+		}
+	}
+
+	return nil
+}
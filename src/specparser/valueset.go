@@ -1,7 +1,7 @@
 package specparser
 
 import (
-	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,6 +11,7 @@ type (
 	ValueExpression string
 	TimeUnitType    int
 	ValueSet        []TimeUnit
+	valueList       []int // The values of an expression while it is expanded
 )
 
 const (
@@ -19,31 +20,79 @@ const (
 	TimeUnitDays
 	TimeUnitMonths
 	TimeUnitDaysOfWeek
+	TimeUnitSeconds
+	TimeUnitYears
 )
 
+// String names the field the unit type is used for, unknown types have no name
+func (t TimeUnitType) String() string {
+	switch t {
+	case TimeUnitMinutes:
+		return "minute"
+	case TimeUnitHours:
+		return "hour"
+	case TimeUnitDays:
+		return "day"
+	case TimeUnitMonths:
+		return "month"
+	case TimeUnitDaysOfWeek:
+		return "day-of-week"
+	case TimeUnitSeconds:
+		return "second"
+	case TimeUnitYears:
+		return "year"
+	}
+
+	return ""
+}
+
 func (v *ValueExpression) IsWildCard() bool {
 	return *v == "*"
 }
 
+// IsNoSpecificValue reports the Quartz '?' marker, it is accepted for day and day of week and matches like a wildcard
+func (v *ValueExpression) IsNoSpecificValue() bool {
+	return *v == "?"
+}
+
+// isStarred reports an expression starting with * or ?, such as * and */15, which cron treats as unrestricted
+func (v *ValueExpression) isStarred() bool {
+	return strings.HasPrefix(string(*v), "*") || strings.HasPrefix(string(*v), "?")
+}
+
 func (v *ValueExpression) ToString() string {
 	return string(*v)
 }
 
 func (v *ValueExpression) IsSimple() bool {
-	return regexp.MustCompile(`^[0-9]{1,2}$`).MatchString(v.ToString())
+	return regexp.MustCompile(`^[0-9]{1,4}$`).MatchString(v.ToString())
 }
 
+// IsList matches two or more comma separated items, each item may be any of the non list expressions
 func (v *ValueExpression) IsList() bool {
-	pattern := `^([0-9]{1,2}(-\s*[0-9]{1,2})?)((,\s*[0-9]{1,2}(-\s*[0-9]{1,2})?)+)$`
-	return regexp.MustCompile(pattern).MatchString(v.ToString())
+	items := strings.Split(v.ToString(), ",")
+
+	if len(items) < 2 {
+		return false
+	}
+
+	for i := range items {
+		item := ValueExpression(items[i])
+
+		if !item.IsWildCard() && !item.IsSimple() && !item.IsRange() && !item.IsInterval() {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (v *ValueExpression) IsRange() bool {
-	return regexp.MustCompile(`^([0-9]{1,2}-\s*[0-9]{1,2})$`).MatchString(v.ToString())
+	return regexp.MustCompile(`^([0-9]{1,4}-\s*[0-9]{1,4})$`).MatchString(v.ToString())
 }
 
 func (v *ValueExpression) IsInterval() bool {
-	return regexp.MustCompile(`^([0-9]{1,2}|\*)(/[0-9]{1,2})$`).MatchString(v.ToString())
+	return regexp.MustCompile(`^([0-9]{1,4}(-[0-9]{1,4})?|\*)(/[0-9]{1,4})$`).MatchString(v.ToString())
 }
 
 func seq(first int, last int, timeUnitType TimeUnitType) (a []TimeUnit) {
@@ -64,6 +113,12 @@ func seq(first int, last int, timeUnitType TimeUnitType) (a []TimeUnit) {
 		case TimeUnitDaysOfWeek:
 			a = append(a, DayOfWeek(i))
 			break
+		case TimeUnitSeconds:
+			a = append(a, Second(i))
+			break
+		case TimeUnitYears:
+			a = append(a, Year(i))
+			break
 		default:
 			panic(0)
 		}
@@ -77,186 +132,413 @@ var Hours = seq(0, 23, TimeUnitHours)
 var Days = seq(1, 31, TimeUnitDays)
 var Months = seq(1, 12, TimeUnitMonths)
 var DaysOfWeek = seq(1, 7, TimeUnitDaysOfWeek)
+var Seconds = seq(0, 59, TimeUnitSeconds)
+var Years = seq(1970, 2099, TimeUnitYears)
+
+var MonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var DayOfWeekNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// QuartzDayOfWeekNames numbers the days from sunday as one, as used by Quartz expressions
+var QuartzDayOfWeekNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
 
-func (e *ValueSet) appendAll(timeUnitType TimeUnitType) (err error) {
+// replaceNames substitutes three letter month and weekday names with their numeric value, names are matched case
+// insensitively and unknown names are left in place to fail later. SUN is written as 7 when it closes a range so that
+// FRI-SUN remains in order. Values are zero padded to the length of the name so offsets into the expression still
+// point at the text that was written.
+func (v ValueExpression) replaceNames(timeUnitType TimeUnitType) ValueExpression {
 	switch timeUnitType {
-	case TimeUnitMinutes:
-		*e = append(*e, Minutes...)
-		break
-	case TimeUnitHours:
-		*e = append(*e, Hours...)
-		break
-	case TimeUnitDays:
-		*e = append(*e, Days...)
-		break
 	case TimeUnitMonths:
-		*e = append(*e, Months...)
-		break
+		return v.substituteNames(MonthNames)
 	case TimeUnitDaysOfWeek:
-		*e = append(*e, DaysOfWeek...)
-		break
+		return v.substituteNames(DayOfWeekNames)
 	default:
-		return errors.New("unknown unit type")
+		return v
+	}
+}
+
+func (v ValueExpression) substituteNames(names map[string]int) ValueExpression {
+	expression := v.ToString()
+	locations := regexp.MustCompile(`[A-Za-z]{3}`).FindAllStringIndex(expression, -1)
+
+	for i := len(locations) - 1; i >= 0; i-- {
+		start, end := locations[i][0], locations[i][1]
+		value, ok := names[strings.ToUpper(expression[start:end])]
+
+		if !ok {
+			continue
+		}
+
+		if value == 0 && start > 0 && expression[start-1] == '-' {
+			value = 7
+		}
+
+		expression = expression[:start] + fmt.Sprintf("%03d", value) + expression[end:]
+	}
+
+	return ValueExpression(expression)
+}
+
+// dayOfWeek maps zero to seven, both are accepted for sunday but only seven is stored
+func dayOfWeek(i int) DayOfWeek {
+	if i == 0 {
+		return DayOfWeek(7)
+	}
+
+	return DayOfWeek(i)
+}
+
+func (e *valueList) appendAll(timeUnitType TimeUnitType) (err error) {
+	first, last, err := bounds(timeUnitType)
+
+	if err != nil {
+		return err
+	}
+
+	for i := first; i <= last; i++ {
+		*e = append(*e, i)
 	}
 
 	return
 }
 
-func (e *ValueSet) appendSimple(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
+func (e *valueList) appendSimple(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
 	intVal, err := strconv.Atoi(valueExpression.ToString())
 
-	switch timeUnitType {
-	case TimeUnitMinutes:
-		*e = append(*e, Minute(intVal))
-		break
-	case TimeUnitHours:
-		*e = append(*e, Hour(intVal))
-		break
-	case TimeUnitDays:
-		*e = append(*e, Day(intVal))
-		break
-	case TimeUnitMonths:
-		*e = append(*e, Month(intVal))
-		break
-	case TimeUnitDaysOfWeek:
-		*e = append(*e, DayOfWeek(intVal))
-		break
-	default:
-		return errors.New("unknown unit type")
+	if err != nil {
+		return newParseError(ReasonInvalidValue, valueExpression.ToString(), 0, "invalid value")
 	}
 
+	if err = checkBounds(intVal, valueExpression.ToString(), 0, timeUnitType); err != nil {
+		return err
+	}
+
+	*e = append(*e, unitValue(intVal, timeUnitType))
+
 	return
 }
 
-func (e *ValueSet) appendRange(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
+func (e *valueList) appendRange(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
 	rangeBoundaries := strings.Split(valueExpression.ToString(), "-")
 
 	min, err := strconv.Atoi(rangeBoundaries[0])
 
 	if err != nil {
-		return errors.New("invalid value at list start")
+		return newParseError(ReasonInvalidValue, rangeBoundaries[0], 0, "invalid value at range start")
 	}
 
 	max, err := strconv.Atoi(rangeBoundaries[1])
 
 	if err != nil {
-		return errors.New("invalid value at list end")
+		return newParseError(ReasonInvalidValue, rangeBoundaries[1], len(rangeBoundaries[0])+1, "invalid value at range end")
 	}
 
-	a := make([]int, max-min+1)
+	if err = checkRange(min, max, rangeBoundaries, timeUnitType); err != nil {
+		return err
+	}
 
-	switch timeUnitType {
-	case TimeUnitMinutes:
-		for i := range a {
-			*e = append(*e, Minute(min+i))
-		}
-		break
-	case TimeUnitHours:
-		for i := range a {
-			*e = append(*e, Hour(min+i))
-		}
-		break
-	case TimeUnitDays:
-		for i := range a {
-			*e = append(*e, Day(min+i))
-		}
-		break
-	case TimeUnitMonths:
-		for i := range a {
-			*e = append(*e, Month(min+i))
-		}
-		break
-	case TimeUnitDaysOfWeek:
-		for i := range a {
-			*e = append(*e, DayOfWeek(min+i))
-		}
-		break
-	default:
-		return errors.New("unknown unit type")
+	for _, i := range rangeValues(min, max, timeUnitType) {
+		*e = append(*e, unitValue(i, timeUnitType))
 	}
 
 	return
 }
 
-func (e *ValueSet) appendInterval(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
+// appendInterval handles start/step where start is a wildcard, a single value running to the end of the unit or a
+// range, the step is applied from the start value and follows a range around the cycle when it wraps
+func (e *valueList) appendInterval(valueExpression ValueExpression, timeUnitType TimeUnitType) (err error) {
 	operands := strings.Split(valueExpression.ToString(), "/")
 
-	var offset = 0
+	first, last, err := bounds(timeUnitType)
 
-	if len(operands) < 2 {
-		operands = append([]string{"0"}, operands...)
+	if err != nil {
+		return err
 	}
 
-	if operands[0] == "*" {
-		offset = 0
-	} else {
-		offset, err = strconv.Atoi(operands[0])
+	// Cron numbers the days of week from sunday as zero, so */2 is sunday, tuesday, thursday and saturday
+	if operands[0] == "*" && timeUnitType == TimeUnitDaysOfWeek {
+		first, last = 0, 6
 	}
 
-	if err != nil {
-		return errors.New("invalid value at list start")
+	if operands[0] != "*" {
+		rangeBoundaries := strings.Split(operands[0], "-")
+
+		if first, err = strconv.Atoi(rangeBoundaries[0]); err != nil {
+			return newParseError(ReasonInvalidValue, rangeBoundaries[0], 0, "invalid value at range start")
+		}
+
+		if len(rangeBoundaries) > 1 {
+			if last, err = strconv.Atoi(rangeBoundaries[1]); err != nil {
+				return newParseError(ReasonInvalidValue, rangeBoundaries[1], len(rangeBoundaries[0])+1, "invalid value at range end")
+			}
+
+			if err = checkRange(first, last, rangeBoundaries, timeUnitType); err != nil {
+				return err
+			}
+		} else if err = checkBounds(first, rangeBoundaries[0], 0, timeUnitType); err != nil {
+			return err
+		}
 	}
 
 	interval, err := strconv.Atoi(operands[1])
 
+	if err != nil || interval < 1 {
+		return newParseError(ReasonInvalidStep, operands[1], len(operands[0])+1, "invalid step")
+	}
+
+	values := rangeValues(first, last, timeUnitType)
+
+	for i := 0; i < len(values); i += interval {
+		*e = append(*e, unitValue(values[i], timeUnitType))
+	}
+
+	return
+}
+
+// checkBounds validates a value against the limits of the unit, day of week also accepts zero for sunday
+func checkBounds(value int, token string, offset int, timeUnitType TimeUnitType) error {
+	first, last, err := bounds(timeUnitType)
+
 	if err != nil {
-		return errors.New("invalid value at list end")
+		return err
+	}
+
+	if timeUnitType == TimeUnitDaysOfWeek {
+		first = 0
+	}
+
+	if value < first || value > last {
+		return newParseError(ReasonOutOfRange, token, offset, fmt.Sprintf("value out of range %d-%d", first, last))
+	}
+
+	return nil
+}
+
+// checkRange validates both ends of a range, only a range of years may not run backwards as years have no cycle to
+// wrap around
+func checkRange(min int, max int, rangeBoundaries []string, timeUnitType TimeUnitType) error {
+	if err := checkBounds(min, rangeBoundaries[0], 0, timeUnitType); err != nil {
+		return err
+	}
+
+	if err := checkBounds(max, rangeBoundaries[1], len(rangeBoundaries[0])+1, timeUnitType); err != nil {
+		return err
+	}
+
+	if min > max && timeUnitType == TimeUnitYears {
+		return newParseError(ReasonReversedRange, rangeBoundaries[0]+"-"+rangeBoundaries[1], 0, "range start is after range end")
+	}
+
+	return nil
+}
+
+// rangeValues lists the values from min to max. A range whose start is after its end wraps around the cycle of the
+// unit, so 22-2 in hours is 22,23,0,1,2, FRI-MON in days of week is friday to monday and 28-3 in days is 28 to 31 then
+// 1 to 3 whatever the length of the month. Sunday closing a wrapped day of week range may be written 0 as well as 7.
+func rangeValues(min int, max int, timeUnitType TimeUnitType) (values []int) {
+	first, last, _ := bounds(timeUnitType)
+
+	if min > max && timeUnitType == TimeUnitDaysOfWeek && max == 0 {
+		max = last
+	}
+
+	if min <= max {
+		for i := min; i <= max; i++ {
+			values = append(values, i)
+		}
+
+		return values
+	}
+
+	for i := min; i <= last; i++ {
+		values = append(values, i)
 	}
 
+	for i := first; i <= max; i++ {
+		values = append(values, i)
+	}
+
+	return values
+}
+
+// bounds returns the first and last value of the unit as defined by the unit sequences
+func bounds(timeUnitType TimeUnitType) (first int, last int, err error) {
+	var units []TimeUnit
+
 	switch timeUnitType {
 	case TimeUnitMinutes:
-		for i := Minutes[0].ToInt(); (i + offset) < Minutes[len(Minutes)-1].ToInt(); i += interval {
-			*e = append(*e, Minute(i+offset))
-		}
+		units = Minutes
 		break
 	case TimeUnitHours:
-		for i := Hours[0].ToInt(); (i + offset) < Hours[len(Hours)-1].ToInt(); i += interval {
-			*e = append(*e, Hour(i+offset))
-		}
+		units = Hours
 		break
 	case TimeUnitDays:
-		for i := Days[0].ToInt(); (i + offset) < Days[len(Days)-1].ToInt(); i += interval {
-			*e = append(*e, Day(i+offset))
-		}
+		units = Days
 		break
 	case TimeUnitMonths:
-		for i := Months[0].ToInt(); (i + offset) < Months[len(Months)-1].ToInt(); i += interval {
-			*e = append(*e, Month(i+offset))
-		}
+		units = Months
 		break
 	case TimeUnitDaysOfWeek:
-		for i := DaysOfWeek[0].ToInt(); (i + offset) <= DaysOfWeek[len(DaysOfWeek)-1].ToInt(); i += interval {
-			*e = append(*e, DayOfWeek(i+offset))
-		}
+		units = DaysOfWeek
+		break
+	case TimeUnitSeconds:
+		units = Seconds
+		break
+	case TimeUnitYears:
+		units = Years
+		break
 	default:
-		err = errors.New("unknown unit type")
+		return 0, 0, newParseError(ReasonUnknownUnit, "", 0, "unknown unit type")
+	}
+
+	return units[0].ToInt(), units[len(units)-1].ToInt(), nil
+}
+
+// unitValue is the value stored for i, sunday given as zero is stored as seven
+func unitValue(i int, timeUnitType TimeUnitType) int {
+	if timeUnitType == TimeUnitDaysOfWeek {
+		return dayOfWeek(i).ToInt()
+	}
+
+	return i
+}
+
+func newTimeUnit(i int, timeUnitType TimeUnitType) (value TimeUnit, err error) {
+	switch timeUnitType {
+	case TimeUnitMinutes:
+		value = Minute(i)
+		break
+	case TimeUnitHours:
+		value = Hour(i)
+		break
+	case TimeUnitDays:
+		value = Day(i)
+		break
+	case TimeUnitMonths:
+		value = Month(i)
+		break
+	case TimeUnitDaysOfWeek:
+		value = dayOfWeek(i)
+		break
+	case TimeUnitSeconds:
+		value = Second(i)
+		break
+	case TimeUnitYears:
+		value = Year(i)
+		break
+	default:
+		err = newParseError(ReasonUnknownUnit, "", 0, "unknown unit type")
 	}
 
 	return
 }
 
-func (e *ValueSet) appendList(timeSpecPart ValueExpression, timeUnitType TimeUnitType) (err error) {
+func (e *valueList) appendList(timeSpecPart ValueExpression, timeUnitType TimeUnitType) (err error) {
 	items := strings.Split(timeSpecPart.ToString(), ",")
+	offset := 0
 
 	for i := range items {
-		values, err := ValueExpression(items[i]).Expand(timeUnitType)
+		values, err := ValueExpression(items[i]).expandValues(timeUnitType)
 		*e = append(*e, values...)
 
 		if err != nil {
-			return err
+			return asParseError(err, ReasonInvalidValue, items[i], 0).shift(offset)
 		}
+
+		offset += len(items[i]) + 1
 	}
 
 	return
 }
 
-type ExpandValues func(timeSpecPart ValueExpression, timeUnitType TimeUnitType) (values ValueSet, err error)
+// invalidItem locates the first list item which is not a valid expression, within that item the first part which is
+// neither a value nor a wildcard is reported when there is one. A part missing around - or / reports the whole item,
+// and an empty item, as in 1,,2, is reported as an empty token at its position.
+func (v ValueExpression) invalidItem() *ParseError {
+	items := strings.Split(v.ToString(), ",")
+	offset := 0
+
+	for i := range items {
+		item := ValueExpression(items[i])
+
+		if items[i] == "" {
+			return newParseError(ReasonInvalidValue, "", offset, "empty list item")
+		}
+
+		if !item.IsWildCard() && !item.IsSimple() && !item.IsRange() && !item.IsInterval() {
+			partOffset := 0
+
+			for _, part := range regexp.MustCompile(`[-/]`).Split(items[i], -1) {
+				if part != "" && part != "*" && !regexp.MustCompile(`^[0-9]{1,4}$`).MatchString(part) && len(part) < len(items[i]) {
+					return newParseError(ReasonInvalidValue, part, offset+partOffset, "invalid value")
+				}
 
+				partOffset += len(part) + 1
+			}
+
+			return newParseError(ReasonInvalidValue, items[i], offset, "invalid value")
+		}
+
+		offset += len(items[i]) + 1
+	}
+
+	return newParseError(ReasonInvalidValue, v.ToString(), 0, "invalid value")
+}
+
+// Expand resolves the expression to the values it matches, failures are reported as a *ParseError with offsets
+// relative to the expression
 func (v ValueExpression) Expand(timeUnitType TimeUnitType) (values ValueSet, err error) {
+	list, err := v.expandValues(timeUnitType)
+
+	for _, i := range list {
+		value, unitErr := newTimeUnit(i, timeUnitType)
+
+		if unitErr != nil {
+			return values, unitErr
+		}
+
+		values = append(values, value)
+	}
+
+	return values, err
+}
+
+// expandBits resolves the expression straight into a bitmask, years are too wide for one and use expandYearBits
+func (v ValueExpression) expandBits(timeUnitType TimeUnitType) (b Bits, err error) {
+	values, err := v.expandValues(timeUnitType)
+
+	return bitsOf(values), err
+}
+
+func (v ValueExpression) expandYearBits() (y YearBits, err error) {
+	values, err := v.expandValues(TimeUnitYears)
+
+	for _, year := range values {
+		y = y.Add(year)
+	}
+
+	return y, err
+}
+
+// expandValues holds the parsing behind Expand, the values are plain ints so nothing is allocated per value
+func (v ValueExpression) expandValues(timeUnitType TimeUnitType) (values valueList, err error) {
+	original := v
+	v = v.replaceNames(timeUnitType)
+
 	switch {
 	case v.IsWildCard():
 		err = values.appendAll(timeUnitType)
 		break
+	case v.IsNoSpecificValue() && (timeUnitType == TimeUnitDays || timeUnitType == TimeUnitDaysOfWeek):
+		err = values.appendAll(timeUnitType)
+		break
 	case v.IsSimple():
 		err = values.appendSimple(v, timeUnitType)
 		break
@@ -270,7 +552,15 @@ func (v ValueExpression) Expand(timeUnitType TimeUnitType) (values ValueSet, err
 		err = values.appendList(v, timeUnitType)
 		break
 	default:
-		err = errors.New("invalid v " + v.ToString())
+		err = v.invalidItem()
+	}
+
+	if parseError, ok := err.(*ParseError); ok {
+		parseError.retoken(original.ToString())
+
+		if parseError.Field == "" {
+			parseError.Field = timeUnitType.String()
+		}
 	}
 
 	return values, err
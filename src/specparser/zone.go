@@ -0,0 +1,40 @@
+package specparser
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ZoneInfoDir is a zoneinfo directory laid out as Region/City files, when empty zones come from the system database
+var ZoneInfoDir = ""
+
+// LoadLocation returns the time zone with the given IANA name, read from ZoneInfoDir when it is set
+func LoadLocation(name string) (*time.Location, error) {
+	if ZoneInfoDir == "" || name == "" || name == "UTC" || name == "Local" {
+		return time.LoadLocation(name)
+	}
+
+	if filepath.IsAbs(name) || strings.Contains(name, "..") {
+		return nil, errors.New("invalid time zone name " + name)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(ZoneInfoDir, filepath.FromSlash(name)))
+
+	if err != nil {
+		return nil, errors.New("unknown time zone " + name)
+	}
+
+	return time.LoadLocationFromTZData(name, data)
+}
+
+// in converts t into the location of the spec, t is unchanged when the spec has no location of its own
+func (s *TaskSpec) in(t time.Time) time.Time {
+	if s.Location == nil {
+		return t
+	}
+
+	return t.In(s.Location)
+}
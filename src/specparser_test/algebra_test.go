@@ -0,0 +1,238 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+	"time"
+)
+
+func schedule(t *testing.T, spec string, mode specparser.ParseMode) *specparser.TimeSpecExtended {
+	taskSpec, err := specparser.NewTaskSpecMode(spec+" command", mode)
+
+	if err != nil {
+		t.Fatal(spec, err)
+	}
+
+	return &taskSpec.Schedule
+}
+
+func TestTimeSpecExtended_Intersect(t *testing.T) {
+	result := schedule(t, "*/15 * * * *", 0).Intersect(schedule(t, "*/10 9-17 * * MON-FRI", 0))
+	single, ok := result.(*specparser.TimeSpecExtended)
+
+	if !ok || single.String() != "0,30 9-17 * * 1-5" {
+		t.Fatal("expecting a single schedule", result)
+	}
+
+	result = schedule(t, "*/20 * * * * *", specparser.ParseSeconds).Intersect(schedule(t, "0 12 * * *", 0))
+
+	if single, ok := result.(*specparser.TimeSpecExtended); !ok || single.String() != "0 0 12 * * *" || result.Resolution() != time.Second {
+		t.Error("a schedule without seconds fires at second zero", result)
+	}
+
+	// Both restrict the day of month and one uses L, so the result is a composite
+	result = schedule(t, "0 0 L * *", 0).Intersect(schedule(t, "0 0 28-31 2 *", 0))
+
+	if _, ok := result.(*specparser.CompositeSchedule); !ok {
+		t.Fatal("expecting a composite", result)
+	}
+
+	next, err := result.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !next.Equal(time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected next", next, err)
+	}
+
+	next, err = result.Next(next)
+
+	if err != nil || !next.Equal(time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected next", next, err)
+	}
+
+	prev, err := result.Prev(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !prev.Equal(time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected prev", prev, err)
+	}
+
+	if _, err := schedule(t, "0 0 L * *", 0).Intersect(schedule(t, "0 0 15W * *", 0)).Next(time.Now()); err != specparser.ErrNeverFires {
+		t.Error("the last day is never the weekday nearest the 15th", err)
+	}
+
+	// No minute in common, the result is the empty schedule rather than a field left blank
+	result = schedule(t, "0 * 1 * 1", 0).Intersect(schedule(t, "30 * * * *", 0))
+
+	if single, ok := result.(*specparser.TimeSpecExtended); !ok || single.String() != specparser.EmptySchedule {
+		t.Fatal("expecting the empty schedule", result)
+	}
+
+	if _, err := result.Next(time.Now()); err != specparser.ErrNeverFires {
+		t.Error("the empty schedule should never fire", err)
+	}
+
+	result = schedule(t, "0 * * * * *", specparser.ParseSeconds).Intersect(schedule(t, "30 * * * * *", specparser.ParseSeconds))
+
+	if single, ok := result.(*specparser.TimeSpecExtended); !ok || single.String() != specparser.EmptySchedule {
+		t.Error("expecting the empty schedule when no second is in common", result)
+	}
+}
+
+func TestTimeSpecExtended_Union(t *testing.T) {
+	result := schedule(t, "0 9 * * 1-5", 0).Union(schedule(t, "0 9 * * 0,6", 0))
+	single, ok := result.(*specparser.TimeSpecExtended)
+
+	if !ok || !single.Equal(schedule(t, "0 9 * * *", 0)) {
+		t.Fatal("expecting a single schedule for every day", result)
+	}
+
+	if result := schedule(t, "0 9 * * 1", 0).Union(schedule(t, "0 9 * * 1-5", 0)); result.(*specparser.TimeSpecExtended).String() != "0 9 * * 1-5" {
+		t.Error("a subset should give the larger schedule", result)
+	}
+
+	result = schedule(t, "0 9 * * *", 0).Union(schedule(t, "30 17 * * *", 0))
+
+	if _, ok := result.(*specparser.CompositeSchedule); !ok {
+		t.Fatal("expecting a composite", result)
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := []time.Time{start.Add(9 * time.Hour), start.Add(17*time.Hour + 30*time.Minute), start.Add(33 * time.Hour)}
+
+	for i := range expected {
+		next, err := result.Next(start)
+
+		if err != nil || !next.Equal(expected[i]) {
+			t.Error("unexpected next", next, expected[i], err)
+		}
+
+		start = next
+	}
+}
+
+func TestTimeSpecExtended_Subtract(t *testing.T) {
+	result := schedule(t, "* 9-17 * * 1-5", 0).Subtract(schedule(t, "* 12 * * *", 0))
+
+	if single, ok := result.(*specparser.TimeSpecExtended); !ok || single.String() != "* 9-11,13-17 * * 1-5" {
+		t.Error("expecting the lunch hour removed", result)
+	}
+
+	if result := schedule(t, "0 9 * * *", 0).Subtract(schedule(t, "0 10 * * *", 0)); result.(*specparser.TimeSpecExtended).String() != "0 9 * * *" {
+		t.Error("removing a disjoint schedule should change nothing", result)
+	}
+
+	result = schedule(t, "*/15 * * * *", 0).Subtract(schedule(t, "0 12 * * *", 0))
+
+	if _, ok := result.(*specparser.CompositeSchedule); !ok {
+		t.Fatal("expecting a composite", result)
+	}
+
+	next, _ := result.Next(time.Date(2026, 1, 1, 11, 50, 0, 0, time.UTC))
+	prev, _ := result.Prev(time.Date(2026, 1, 1, 12, 10, 0, 0, time.UTC))
+
+	if !next.Equal(time.Date(2026, 1, 1, 12, 15, 0, 0, time.UTC)) || !prev.Equal(time.Date(2026, 1, 1, 11, 45, 0, 0, time.UTC)) {
+		t.Error("12:00 should be skipped", next, prev)
+	}
+
+	if result.Matches(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) || !result.Matches(time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected match")
+	}
+}
+
+func TestTimeSpecExtended_EitherDay(t *testing.T) {
+	either := schedule(t, "0 0 1 * MON", 0)
+
+	if result, ok := either.Intersect(schedule(t, "0 0 * * *", 0)).(*specparser.TimeSpecExtended); !ok || !result.Equal(either) {
+		t.Error("intersecting with every day should keep the schedule", result)
+	}
+
+	if result := either.Intersect(schedule(t, "0 0 * * MON", 0)); !result.(*specparser.CompositeSchedule).Matches(time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC)) ||
+		result.Matches(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expecting every Monday only", result)
+	}
+
+	// The 1st which is a Monday has no standard cron line, as 0 0 1 * 1 matches either day
+	composite, ok := schedule(t, "0 0 1 * *", 0).Intersect(schedule(t, "0 0 * * MON", 0)).(*specparser.CompositeSchedule)
+
+	if !ok || composite.Matches(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)) || !composite.Matches(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("intersecting the 1st with Mondays should need both", composite)
+	}
+
+	if _, ok := schedule(t, "0 0 * * MON", 0).Subtract(schedule(t, "0 0 1 * *", 0)).(*specparser.CompositeSchedule); !ok {
+		t.Error("Mondays other than the 1st have no standard cron line")
+	}
+
+	// Read with ParseStrictDays the line needs both, so the result can be a single schedule
+	result, ok := schedule(t, "0 0 1 * *", specparser.ParseStrictDays).Intersect(schedule(t, "0 0 * * MON", specparser.ParseStrictDays)).(*specparser.TimeSpecExtended)
+
+	if !ok || !result.StrictDays || result.String() != "0 0 1 * 1" || result.Matches(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("intersecting strict schedules should need both", result)
+	}
+
+	if union, ok := either.Union(schedule(t, "0 0 15 * MON", 0)).(*specparser.TimeSpecExtended); !ok || union.String() != "0 0 1,15 * 1" || !union.EitherDay() {
+		t.Error("expecting the days merged", union)
+	}
+
+	if _, ok := either.Union(schedule(t, "0 0 15 * TUE", specparser.ParseStrictDays)).(*specparser.CompositeSchedule); !ok {
+		t.Error("a strict schedule can not be merged with one matching either day")
+	}
+
+	if result := either.Subtract(schedule(t, "0 0 * * MON", 0)); result.Matches(time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC)) ||
+		!result.Matches(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expecting the 1st when not a Monday", result)
+	}
+
+	if _, err := either.Subtract(schedule(t, "* * * * *", 0)).Next(time.Now()); err != specparser.ErrNeverFires {
+		t.Error("removing every minute should leave nothing", err)
+	}
+}
+
+func TestTimeSpecExtended_IsSubset(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		subset bool
+		equal  bool
+	}{
+		{"0 9 * * 1", "0 9 * * 1-5", true, false},
+		{"0 9 * * 1-5", "0 9 * * 1", false, false},
+		{"0 0 L * *", "0 0 28-31 * *", true, false},
+		{"0 0 28-31 * *", "0 0 L * *", false, false},
+		{"0 0 L 2 *", "0 0 28,29 2 *", true, false},
+		{"0 0 1-31 * *", "0 0 * * *", true, true},
+		{"* * * * 0", "* * * * 7", true, true},
+		{"0,1,2,3 * * * *", "0-3 * * * *", true, true},
+		{"0 0 30 2 *", "0 12 1 1 *", true, false},
+		{"0 0 31 * *", "0 0 * 1,3,5,7,8,10,12 *", true, false},
+		{"0 0 1 * 1", "0 0 * * *", true, false},
+		{"0 0 1 * 1", "0 0 1 * *", false, false},
+		{"0 0 1 * *", "0 0 1 * 1", true, false},
+		{"0 0 * * 1", "0 0 1-31 * 1", true, false},
+	}
+
+	for _, c := range cases {
+		a, b := schedule(t, c.a, 0), schedule(t, c.b, 0)
+
+		if a.IsSubset(b) != c.subset || a.Equal(b) != c.equal {
+			t.Error("unexpected comparison", c.a, c.b, a.IsSubset(b), a.Equal(b))
+		}
+	}
+}
+
+func TestNewTaskList_Composite(t *testing.T) {
+	taskSpec := specparser.TaskSpec{
+		Timetable: schedule(t, "0 9 * * *", 0).Union(schedule(t, "30 9 * * 1-5", 0)).(*specparser.CompositeSchedule).Subtract(schedule(t, "* * 1 * *", 0)),
+		Command:   "command",
+	}
+
+	// Thursday 1 January is excluded, Friday 2 January has both times
+	taskList, _ := specparser.NewTaskList(taskSpec, time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC), 26*60)
+
+	if len(taskList.Schedule) != 2 || !taskList.Schedule[0].Equal(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)) || !taskList.Schedule[1].Equal(time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC)) {
+		t.Error("unexpected schedule", taskList.Schedule)
+	}
+
+	next, err := taskSpec.Next(time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC))
+
+	if err != nil || !next.Equal(time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected next", next, err)
+	}
+}
@@ -0,0 +1,171 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+	"time"
+)
+
+func TestBits(t *testing.T) {
+	a := specparser.NewBits([]specparser.TimeUnit{specparser.Minute(0), specparser.Minute(15), specparser.Minute(59)})
+	b := specparser.Bits(0).Add(15).Add(30).Add(64)
+
+	if !a.Has(0) || !a.Has(59) || a.Has(1) || a.Has(-1) || a.Has(64) {
+		t.Error("unexpected membership", a.Values())
+	}
+
+	if a.Count() != 3 || b.Count() != 2 {
+		t.Error("unexpected count", a.Count(), b.Count())
+	}
+
+	if values := a.Union(b).Values(); len(values) != 4 || values[0] != 0 || values[3] != 59 {
+		t.Error("unexpected union", values)
+	}
+
+	if values := a.Intersect(b).Values(); len(values) != 1 || values[0] != 15 {
+		t.Error("unexpected intersection", values)
+	}
+
+	if values := a.Subtract(b).Values(); len(values) != 2 || values[0] != 0 || values[1] != 59 {
+		t.Error("unexpected difference", values)
+	}
+
+	if !a.Intersect(b).IsSubset(a) || a.IsSubset(b) {
+		t.Error("unexpected subset")
+	}
+}
+
+func TestYearBits(t *testing.T) {
+	years, _ := specparser.ValueExpression("1970,2026-2028,2099").Expand(specparser.TimeUnitYears)
+	y := specparser.NewYearBits(years)
+
+	if !y.Has(1970) || !y.Has(2027) || !y.Has(2099) || y.Has(2029) || y.Has(1969) || y.Has(3000) {
+		t.Error("unexpected membership", y.Values())
+	}
+
+	if y.Count() != 5 || y.Values()[4] != 2099 {
+		t.Error("unexpected values", y.Values())
+	}
+
+	other := specparser.YearBits{}.Add(2027).Add(2050)
+
+	if y.Intersect(other).Count() != 1 || y.Union(other).Count() != 6 || y.Subtract(other).Count() != 4 {
+		t.Error("unexpected set operations")
+	}
+
+	if !y.Intersect(other).IsSubset(y) || other.IsSubset(y) {
+		t.Error("unexpected subset")
+	}
+}
+
+func TestTimeSpecExtended_Bits(t *testing.T) {
+	taskSpec, _ := specparser.NewTaskSpec("1-15,42-46 9-17 * JAN,JUL MON-FRI command")
+	b := taskSpec.Schedule
+
+	if b.Minutes.Count() != 20 || b.Hours.Count() != 9 || b.Days.Count() != 31 || b.Months.Count() != 2 || b.DaysOfWeek.Count() != 5 {
+		t.Error("unexpected bit counts", b)
+	}
+
+	if b.HasSeconds() || !b.AnyYear() {
+		t.Error("five field spec has no seconds or year restriction", b)
+	}
+
+	// A copy is independent of the original, there is no shared state behind the fields
+	copied := taskSpec
+	copied.Schedule.Minutes = copied.Schedule.Minutes.Add(30)
+
+	if taskSpec.HasMinute(30) || !copied.HasMinute(30) {
+		t.Error("changing a copy should not change the original")
+	}
+}
+
+// sliceSchedule holds the fields as they were held before the schedule was made of bitmasks
+type sliceSchedule struct {
+	minutes, hours, days, months, daysOfWeek specparser.ValueSet
+}
+
+func newSliceSchedule(minute, hour, day, month, dayOfWeek string) (s sliceSchedule) {
+	s.minutes, _ = specparser.ValueExpression(minute).Expand(specparser.TimeUnitMinutes)
+	s.hours, _ = specparser.ValueExpression(hour).Expand(specparser.TimeUnitHours)
+	s.days, _ = specparser.ValueExpression(day).Expand(specparser.TimeUnitDays)
+	s.months, _ = specparser.ValueExpression(month).Expand(specparser.TimeUnitMonths)
+	s.daysOfWeek, _ = specparser.ValueExpression(dayOfWeek).Expand(specparser.TimeUnitDaysOfWeek)
+
+	return s
+}
+
+// sliceHas is the membership check used before the schedule was held as bitmasks
+func sliceHas(values []specparser.TimeUnit, value specparser.TimeUnit) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func BenchmarkHasMinuteSlice(b *testing.B) {
+	schedule := newSliceSchedule("1-15,42-46,55,57,59", "*", "*", "*", "*")
+
+	for i := 0; i < b.N; i++ {
+		sliceHas(schedule.minutes, specparser.Minute(i%60))
+	}
+}
+
+func BenchmarkHasMinuteBits(b *testing.B) {
+	taskSpec, _ := specparser.NewTaskSpec("1-15,42-46,55,57,59 * * * * command")
+
+	for i := 0; i < b.N; i++ {
+		taskSpec.HasMinute(specparser.Minute(i % 60))
+	}
+}
+
+// sliceMatches checks a time against the spec the way NewTaskList did before the schedule was held as bitmasks
+func sliceMatches(schedule sliceSchedule, t time.Time) bool {
+	dayOfWeek := specparser.DayOfWeek(t.Weekday())
+
+	if dayOfWeek == 0 {
+		dayOfWeek = 7
+	}
+
+	return sliceHas(schedule.months, specparser.Month(t.Month())) &&
+		sliceHas(schedule.daysOfWeek, dayOfWeek) &&
+		sliceHas(schedule.days, specparser.Day(t.Day())) &&
+		sliceHas(schedule.hours, specparser.Hour(t.Hour())) &&
+		sliceHas(schedule.minutes, specparser.Minute(t.Minute()))
+}
+
+func BenchmarkMatchesSlice(b *testing.B) {
+	schedule := newSliceSchedule("59", "23", "*", "*", "*")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		sliceMatches(schedule, start.Add(time.Duration(i%1440)*time.Minute))
+	}
+}
+
+func BenchmarkMatchesBits(b *testing.B) {
+	taskSpec, _ := specparser.NewTaskSpec("59 23 * * * command")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		taskSpec.Matches(start.Add(time.Duration(i%1440) * time.Minute))
+	}
+}
+
+func BenchmarkExpandSlice(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		newSliceSchedule("*", "*", "*", "*", "*")
+	}
+}
+
+func BenchmarkExplodeBits(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		specparser.TimeExpression{}.New("*", "*", "*", "*", "*").Explode()
+	}
+}
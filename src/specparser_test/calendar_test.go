@@ -0,0 +1,161 @@
+package specparser_test
+
+import (
+	"errors"
+	"specparser"
+	"testing"
+	"time"
+)
+
+func TestNewTaskSpecCalendar(t *testing.T) {
+	var cases = []struct {
+		calendar string
+		schedule string
+	}{
+		{"Mon..Fri *-*-* 09:00:00", "0 9 * * 1-5"},
+		{"*-*-01 04:00", "0 4 1 * *"},
+		{"Sat,Sunday 10:30", "30 10 * * 0,6"},
+		{"2026-12-25", "0 0 25 12 * 2026"},
+		{"12-25 18:00", "0 18 25 12 *"},
+		{"*-02~03 12:00", "0 12 L-2 2 *"},
+		{"*-*~1 23:59", "59 23 L * *"},
+		{"*:0/15", "0-59/15 * * * *"},
+		{"*-*-* 08..17:00:30", "30 0 8-17 * * *"},
+		{"Fri..Sun,Mon 22:00", "0 22 * * 1,5-7"},
+		{"daily", "0 0 * * *"},
+		{"weekly", "0 0 * * 1"},
+		{"quarterly", "0 0 1 */3 *"},
+	}
+
+	for _, c := range cases {
+		taskSpec, err := specparser.NewTaskSpecCalendar(c.calendar, "command")
+
+		if err != nil {
+			t.Error("unexpected error", c.calendar, err)
+			continue
+		}
+
+		if schedule := taskSpec.Schedule.String(); schedule != c.schedule {
+			t.Errorf("%s: expected %q, got %q", c.calendar, c.schedule, schedule)
+		}
+	}
+}
+
+func TestNewTaskSpecCalendar_Next(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpecCalendar("Mon *-*-01 06:00", "command")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both the weekday and the day of month have to match, the next Monday the 1st is in February 2027
+	next, err := taskSpec.Next(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !next.Equal(time.Date(2027, 2, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected next", next, err)
+	}
+
+	list, err := specparser.NewTaskList(taskSpec, time.Date(2027, 2, 1, 5, 0, 0, 0, time.UTC), 120)
+
+	if err != nil || len(list.Schedule) != 1 {
+		t.Error("expecting one run in the task list", list.Schedule, err)
+	}
+}
+
+func TestNewTaskSpecCalendar_Location(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	taskSpec, err := specparser.NewTaskSpecCalendar("*-*-* 09:00 Asia/Tokyo", "command")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if taskSpec.Location == nil || taskSpec.Location.String() != "Asia/Tokyo" {
+		t.Fatal("expecting the Tokyo zone", taskSpec.Location)
+	}
+
+	if calendar, _ := taskSpec.OnCalendar(); calendar != "*-*-* 09:00:00 Asia/Tokyo" {
+		t.Error("unexpected calendar", calendar)
+	}
+}
+
+func TestNewTaskSpecCalendar_Errors(t *testing.T) {
+	var cases = []struct {
+		calendar string
+		field    string
+		offset   int
+		token    string
+	}{
+		{"Mon..Fry 09:00", "calendar", 0, "Mon..Fry"},
+		{"Sun..Mon 09:00", "calendar", 0, "Sun..Mon"},
+		{"*-*-* 09:00:00.5", "calendar", 6, "09:00:00.5"},
+		{"*-*-* 9", "calendar", 6, "9"},
+		{"*-13-01", "month", 0, "*-13-01"},
+		{"*-*-* 25:00", "hour", 6, "25:00"},
+		{"*-*-* 09:00 Mars/Olympus", "calendar", 12, "Mars/Olympus"},
+		{"", "calendar", 0, ""},
+	}
+
+	for _, c := range cases {
+		_, err := specparser.NewTaskSpecCalendar(c.calendar, "command")
+
+		var parseError *specparser.ParseError
+
+		if !errors.As(err, &parseError) {
+			t.Error("expecting ParseError", c.calendar, err)
+			continue
+		}
+
+		if parseError.Field != c.field || parseError.Offset != c.offset || parseError.Token != c.token {
+			t.Errorf("unexpected error for %q: %+v", c.calendar, parseError)
+		}
+	}
+}
+
+func TestTaskSpec_OnCalendar(t *testing.T) {
+	var cases = []struct {
+		spec     string
+		mode     specparser.ParseMode
+		calendar string
+	}{
+		{"0 9 * * 1-5", specparser.ParseStandard, "Mon..Fri *-*-* 09:00:00"},
+		{"0 4 1 * *", specparser.ParseStandard, "*-*-01 04:00:00"},
+		{"*/15 * * * *", specparser.ParseStandard, "*-*-* *:00/15:00"},
+		{"0 0 L * *", specparser.ParseStandard, "*-*~1 00:00:00"},
+		{"30 0 12 ? * SAT,SUN 2027", specparser.ParseQuartz, "Sat,Sun 2027-*-* 12:00:30"},
+		{"0 0 1,15 1-6 *", specparser.ParseStandard, "*-01..06-01,15 00:00:00"},
+		{"0 0 1 * MON", specparser.ParseStrictDays, "Mon *-*-01 00:00:00"},
+	}
+
+	for _, c := range cases {
+		taskSpec, err := specparser.NewTaskSpecMode(c.spec+" command", c.mode)
+
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+
+		calendar, err := taskSpec.OnCalendar()
+
+		if err != nil || calendar != c.calendar {
+			t.Errorf("%s: expected %q, got %q %v", c.spec, c.calendar, calendar, err)
+			continue
+		}
+
+		// Reading the calendar back gives the same fire times
+		again, err := specparser.NewTaskSpecCalendar(calendar, "command")
+
+		if err != nil || !again.Schedule.Equal(&taskSpec.Schedule) {
+			t.Error("calendar does not read back to the same schedule", calendar, err)
+		}
+	}
+
+	for _, spec := range []string{"0 0 1 * MON command", "0 0 15W * * command", "0 0 * * 5#2 command", "@reboot command"} {
+		taskSpec, _ := specparser.NewTaskSpec(spec)
+
+		if _, err := taskSpec.OnCalendar(); err == nil {
+			t.Error("expecting no calendar equivalent", spec)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+)
+
+var canonicalCases = []struct {
+	spec      string
+	mode      specparser.ParseMode
+	canonical string
+}{
+	{"0,1,2,3 * * * *", specparser.ParseStandard, "0-3 * * * *"},
+	{"0-3 * * * *", specparser.ParseStandard, "0-3 * * * *"},
+	{"1-15,42-46,55,57,59 9-17 * * 1-5", specparser.ParseStandard, "1-15,42-46,55-59/2 9-17 * * 1-5"},
+	{"*/15 */2 * * *", specparser.ParseStandard, "*/15 */2 * * *"},
+	{"0,15,30,45 0-23/2 * * *", specparser.ParseStandard, "0-59/15 0-23/2 * * *"},
+	{"5/10 * * * *", specparser.ParseStandard, "5-59/10 * * * *"},
+	{"0-59 2 * * *", specparser.ParseStandard, "0-59 2 * * *"},
+	{"0 0 L,1,15W * 5L", specparser.ParseStandard, "0 0 1,L,15W * 5L"},
+	{"0 0 * * SAT,SUN", specparser.ParseStandard, "0 0 * * 0,6"},
+	{"0 0 * * 5-7", specparser.ParseStandard, "0 0 * * 5-7"},
+	{"0 0 * * */2", specparser.ParseStandard, "0 0 * * */2"},
+	{"0 0 * * 0,2,4,6", specparser.ParseStandard, "0 0 * * 0-6/2"},
+	{"0 9 * * 0,7", specparser.ParseStandard, "0 9 * * 0"},
+	{"0 0 * JAN-MAR,DEC FRI#2", specparser.ParseStandard, "0 0 * 1-3,12 5#2"},
+	{"0 0 1 1,4,7,10 *", specparser.ParseStandard, "0 0 1 */3 *"},
+	{"@daily", specparser.ParseStandard, "0 0 * * *"},
+	{"*/20 0 12 ? * 2#1 2026,2027,2028", specparser.ParseQuartz, "*/20 0 12 * * 1#1 2026-2028"},
+	{"0 0 12 ? * 1 *", specparser.ParseQuartz, "0 0 12 * * 0"},
+}
+
+func TestTimeSpecExtended_String(t *testing.T) {
+	for _, c := range canonicalCases {
+		taskSpec, err := specparser.NewTaskSpecMode(c.spec+" command", c.mode)
+
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+
+		canonical := taskSpec.Schedule.String()
+
+		if canonical != c.canonical {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.canonical, canonical)
+			continue
+		}
+
+		// The canonical form reads back as the same schedule and is its own canonical form
+		reparsed, err := specparser.NewTaskSpecMode(canonical+" command", c.mode&^specparser.ParseQuartzDayOfWeek)
+
+		if err != nil {
+			t.Error(c.spec, "canonical form does not parse", canonical, err)
+			continue
+		}
+
+		if !sameFields(&reparsed.Schedule, &taskSpec.Schedule) || reparsed.Schedule.String() != canonical {
+			t.Error(c.spec, "canonical form changes the schedule", canonical, reparsed.Schedule.String())
+		}
+	}
+}
+
+func TestTaskSpec_Key(t *testing.T) {
+	a, _ := specparser.NewTaskSpec("0,1,2,3 * * * * /scripts/run.sh  --all")
+	b, _ := specparser.NewTaskSpec("0-3 * * * * /scripts/run.sh  --all")
+	c, _ := specparser.NewTaskSpec("0-3 * * * * /scripts/run.sh --all")
+	d, _ := specparser.NewTaskSpecMode("0-3 * * * * root /scripts/run.sh  --all", specparser.ParseSystem)
+
+	if a.Key() != b.Key() {
+		t.Error("equivalent jobs should share a key", a.Key(), b.Key())
+	}
+
+	if b.Key() == c.Key() || b.Key() == d.Key() {
+		t.Error("jobs with a different command or user should not share a key", c.Key(), d.Key())
+	}
+
+	reboot, _ := specparser.NewTaskSpec("@reboot /scripts/run.sh  --all")
+
+	if reboot.Key() == a.Key() {
+		t.Error("@reboot should not share a key with a schedule")
+	}
+}
+
+func TestTaskSpec_String(t *testing.T) {
+	reboot, _ := specparser.NewTaskSpec("@reboot /scripts/run.sh")
+	daily, _ := specparser.NewTaskSpec("@daily /scripts/run.sh")
+
+	if reboot.String() != specparser.MacroReboot || daily.String() != "0 0 * * *" {
+		t.Error("expecting @reboot and the canonical schedule", reboot.String(), daily.String())
+	}
+
+	empty := daily.Schedule
+	empty.Minutes = 0
+
+	if empty.String() != specparser.EmptySchedule {
+		t.Error("expecting a schedule with an empty field to render as", specparser.EmptySchedule, "got", empty.String())
+	}
+}
+
+// sameFields compares the values of every field, the stars and rules are left to the comparison of the rendered forms
+func sameFields(a *specparser.TimeSpecExtended, b *specparser.TimeSpecExtended) bool {
+	return a.Seconds == b.Seconds && a.Minutes == b.Minutes && a.Hours == b.Hours && a.Days == b.Days &&
+		a.Months == b.Months && a.DaysOfWeek == b.DaysOfWeek && a.Years == b.Years
+}
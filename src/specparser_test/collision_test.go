@@ -0,0 +1,87 @@
+package specparser_test
+
+import (
+	"specparser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCollisionReport(t *testing.T) {
+	crontab, _ := specparser.ParseCrontab(strings.NewReader(`0 2 * * * /scripts/backup.sh
+0 2 * * * /scripts/etl.sh
+30 2 * * * /scripts/report.sh
+*/30 * * * * /scripts/poll.sh
+`), specparser.ParseStandard)
+
+	durations := map[string]time.Duration{
+		crontab.Tasks[0].Key(): time.Hour,
+		crontab.Tasks[1].Key(): 45 * time.Minute,
+		crontab.Tasks[2].Key(): 10 * time.Minute,
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	report, err := crontab.Collisions(at(1, 55), 60, durations)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Collisions) != 2 || !report.Collisions[0].Time.Equal(at(2, 0)) || !report.Collisions[1].Time.Equal(at(2, 30)) {
+		t.Fatal("unexpected collisions", report.Collisions)
+	}
+
+	if len(report.Collisions[0].Tasks) != 3 || report.Collisions[1].Tasks[0].Command != "/scripts/report.sh" {
+		t.Error("unexpected jobs in collision", report.Collisions)
+	}
+
+	if report.PeakSlot != 3 || !report.PeakSlotTime.Equal(at(2, 0)) {
+		t.Error("unexpected peak slot", report.PeakSlot, report.PeakSlotTime)
+	}
+
+	expected := []struct {
+		start, end time.Time
+		runs       int
+	}{
+		{at(2, 0), at(2, 30), 2},
+		{at(2, 30), at(2, 40), 3},
+		{at(2, 40), at(2, 45), 2},
+	}
+
+	if len(report.Overlaps) != len(expected) {
+		t.Fatal("unexpected overlaps", report.Overlaps)
+	}
+
+	for i := range expected {
+		overlap := report.Overlaps[i]
+
+		if !overlap.Start.Equal(expected[i].start) || !overlap.End.Equal(expected[i].end) || len(overlap.Runs) != expected[i].runs {
+			t.Error("unexpected overlap", overlap.Start, overlap.End, len(overlap.Runs))
+		}
+	}
+
+	if report.PeakConcurrency != 3 || !report.PeakConcurrencyTime.Equal(at(2, 30)) {
+		t.Error("unexpected peak concurrency", report.PeakConcurrency, report.PeakConcurrencyTime)
+	}
+}
+
+func TestNewCollisionReport_BackToBack(t *testing.T) {
+	taskSpec, _ := specparser.NewTaskSpec("*/10 * * * * /scripts/sync.sh")
+	durations := map[string]time.Duration{taskSpec.Key(): 10 * time.Minute}
+
+	report, _ := specparser.NewCollisionReport([]specparser.TaskSpec{taskSpec}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 60, durations)
+
+	if len(report.Collisions) != 0 || len(report.Overlaps) != 0 || report.PeakConcurrency != 1 {
+		t.Error("a run ending as the next starts should not overlap", report.Overlaps)
+	}
+
+	durations[taskSpec.Key()] = 15 * time.Minute
+	report, _ = specparser.NewCollisionReport([]specparser.TaskSpec{taskSpec}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 60, durations)
+
+	if len(report.Overlaps) != 5 || report.PeakConcurrency != 2 {
+		t.Error("a run outlasting its interval should overlap the next run", len(report.Overlaps), report.PeakConcurrency)
+	}
+}
@@ -0,0 +1,98 @@
+package specparser_test
+
+import (
+	"os/user"
+	"specparser"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTaskSpec_Cmd(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpec(`* * * * * echo "$GREETING"; cat%first%second`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := taskSpec.Cmd(map[string]string{"GREETING": "hello"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cmd.Args) != 3 || cmd.Args[0] != "/bin/sh" || cmd.Args[1] != "-c" || cmd.Args[2] != taskSpec.Command {
+		t.Error("expecting the command to run through /bin/sh -c", cmd.Args)
+	}
+
+	// The command really runs, with the crontab environment and the text after % on its standard input
+	output, err := cmd.Output()
+
+	if err != nil || string(output) != "hello\nfirst\nsecond\n" {
+		t.Errorf("unexpected output %q %v", output, err)
+	}
+}
+
+func TestTaskSpec_CmdUser(t *testing.T) {
+	current, err := user.Current()
+
+	if err != nil {
+		t.Skip("no current user", err)
+	}
+
+	groups, _ := current.GroupIds()
+	taskSpec, err := specparser.NewTaskSpecMode("* * * * * "+current.Username+" true", specparser.ParseSystem)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The command is only prepared, switching user needs root even to switch to the current one
+	cmd, err := taskSpec.Cmd(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential := cmd.SysProcAttr.Credential
+
+	if credential == nil || strings.Join(uintStrings(credential.Groups), ",") != strings.Join(groups, ",") {
+		t.Error("expecting the supplementary groups of the user", credential, groups)
+	}
+
+	if home := lastValue(cmd.Env, "HOME"); home != current.HomeDir || lastValue(cmd.Env, "LOGNAME") != current.Username {
+		t.Error("expecting HOME and LOGNAME of the user", home)
+	}
+
+	// HOME set by the crontab is kept
+	cmd, _ = taskSpec.Cmd(map[string]string{"HOME": "/srv/jobs"})
+
+	if home := lastValue(cmd.Env, "HOME"); home != "/srv/jobs" {
+		t.Error("expecting HOME from the crontab", home)
+	}
+
+	taskSpec.User = "no-such-user-here"
+
+	if _, err = taskSpec.Cmd(nil); err == nil {
+		t.Error("expecting an unknown user to fail")
+	}
+}
+
+// lastValue finds the value a process sees for name, the last assignment wins
+func lastValue(environment []string, name string) (value string) {
+	for _, assignment := range environment {
+		if strings.HasPrefix(assignment, name+"=") {
+			value = strings.TrimPrefix(assignment, name+"=")
+		}
+	}
+
+	return value
+}
+
+func uintStrings(values []uint32) (result []string) {
+	for _, value := range values {
+		result = append(result, strconv.FormatUint(uint64(value), 10))
+	}
+
+	return result
+}
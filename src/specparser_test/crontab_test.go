@@ -0,0 +1,107 @@
+package specparser_test
+
+import (
+	"errors"
+	"specparser"
+	"strings"
+	"testing"
+	"time"
+)
+
+const crontabFile = `# nightly jobs
+SHELL=/bin/sh
+MAILTO = "ops@example.com"
+PATH='/usr/bin:/bin'
+
+0 2 * * * /scripts/backup.sh
+  # indented comment
+*/15 * * * * /scripts/poll.sh
+@reboot /scripts/start.sh
+`
+
+func TestParseCrontab(t *testing.T) {
+	crontab, err := specparser.ParseCrontab(strings.NewReader(crontabFile), specparser.ParseStandard)
+
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(crontab.Tasks) != 3 {
+		t.Fatal("expecting three jobs", crontab.Tasks)
+	}
+
+	lines := []int{6, 8, 9}
+
+	for i := range lines {
+		if crontab.Tasks[i].Line != lines[i] {
+			t.Error("unexpected line number", crontab.Tasks[i].Command, crontab.Tasks[i].Line)
+		}
+	}
+
+	if !crontab.Tasks[2].Reboot || crontab.Tasks[1].Command != "/scripts/poll.sh" {
+		t.Error("unexpected jobs", crontab.Tasks)
+	}
+
+	expected := map[string]string{"SHELL": "/bin/sh", "MAILTO": "ops@example.com", "PATH": "/usr/bin:/bin"}
+
+	for name, value := range expected {
+		if crontab.Environment[name] != value {
+			t.Error("unexpected environment", name, crontab.Environment[name])
+		}
+	}
+}
+
+func TestParseCrontabCollectsErrors(t *testing.T) {
+	file := "0 2 * * * good\n61 * * * * bad\n\n* * * command\n@daily also-good\n"
+	crontab, err := specparser.ParseCrontab(strings.NewReader(file), specparser.ParseStandard)
+
+	if len(crontab.Tasks) != 2 {
+		t.Error("valid jobs should still be returned", crontab.Tasks)
+	}
+
+	var crontabError *specparser.CrontabError
+
+	if !errors.As(err, &crontabError) || len(crontabError.Errors) != 2 {
+		t.Fatal("expecting a CrontabError with two line errors", err)
+	}
+
+	if crontabError.Errors[0].Line != 2 || crontabError.Errors[1].Line != 4 {
+		t.Error("unexpected line numbers", crontabError.Errors)
+	}
+
+	var parseError *specparser.ParseError
+
+	if !errors.As(err, &parseError) || parseError.Reason != specparser.ReasonOutOfRange {
+		t.Error("expecting the ParseError of the first line", err)
+	}
+
+	if !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Error("unexpected message", err)
+	}
+}
+
+func TestCrontab_TaskList(t *testing.T) {
+	crontab, _ := specparser.ParseCrontab(strings.NewReader("*/5 * * * * first\n0,10 * * * * second\n"), specparser.ParseStandard)
+	start := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+	taskList, err := crontab.TaskList(start, 15)
+
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(taskList.Schedule) != 3 {
+		t.Fatal("unexpected schedule", taskList.Schedule)
+	}
+
+	for i := 1; i < len(taskList.Schedule); i++ {
+		if !taskList.Schedule[i-1].Before(taskList.Schedule[i]) {
+			t.Error("schedule should be sorted", taskList.Schedule)
+		}
+	}
+
+	shared := taskList.Work[time.Date(2026, 1, 1, 12, 10, 30, 0, time.UTC)]
+
+	if len(shared) != 2 || shared[0].Command != "first" || shared[1].Command != "second" {
+		t.Error("expecting both jobs at 12:10", shared)
+	}
+}
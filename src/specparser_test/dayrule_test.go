@@ -0,0 +1,73 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+	"time"
+)
+
+func TestDayRules(t *testing.T) {
+	var cases = []struct {
+		spec     string
+		mode     specparser.ParseMode
+		year     int
+		month    time.Month
+		expected []int
+	}{
+		{"0 0 L * * command", specparser.ParseStandard, 2026, time.February, []int{28}},
+		{"0 0 L * * command", specparser.ParseStandard, 2028, time.February, []int{29}},
+		{"0 0 L-2 * * command", specparser.ParseStandard, 2026, time.February, []int{26}},
+		{"0 0 15W * * command", specparser.ParseStandard, 2026, time.August, []int{14}},
+		{"0 0 15W * * command", specparser.ParseStandard, 2026, time.February, []int{16}},
+		{"0 0 1W * * command", specparser.ParseStandard, 2026, time.August, []int{3}},
+		{"0 0 31W * * command", specparser.ParseStandard, 2026, time.May, []int{29}},
+		{"0 0 31W * * command", specparser.ParseStandard, 2026, time.February, nil},
+		{"0 0 LW * * command", specparser.ParseStandard, 2026, time.May, []int{29}},
+		{"0 0 LW * * command", specparser.ParseStandard, 2026, time.January, []int{30}},
+		{"0 0 1,15,L * * command", specparser.ParseStandard, 2026, time.February, []int{1, 15, 28}},
+		{"0 0 * * 5L command", specparser.ParseStandard, 2026, time.January, []int{30}},
+		{"0 0 * * FRIL command", specparser.ParseStandard, 2026, time.January, []int{30}},
+		{"0 0 * * 0L command", specparser.ParseStandard, 2026, time.May, []int{31}},
+		{"0 0 * * 2#3 command", specparser.ParseStandard, 2026, time.February, []int{17}},
+		{"0 0 * * MON#1,SAT command", specparser.ParseStandard, 2026, time.February, []int{2, 7, 14, 21, 28}},
+		{"0 0 0 ? * 3#3 command", specparser.ParseQuartz, 2026, time.February, []int{17}},
+		{"0 0 0 ? * 6L command", specparser.ParseQuartz, 2026, time.January, []int{30}},
+	}
+
+	for _, c := range cases {
+		taskSpec, err := specparser.NewTaskSpecMode(c.spec, c.mode)
+
+		if err != nil {
+			t.Error("unexpected error", c.spec, err)
+			continue
+		}
+
+		var actual []int
+
+		for day := time.Date(c.year, c.month, 1, 0, 0, 0, 0, time.UTC); day.Month() == c.month; day = day.AddDate(0, 0, 1) {
+			if taskSpec.MatchDay(day) && taskSpec.MatchDayOfWeek(day) {
+				actual = append(actual, day.Day())
+			}
+		}
+
+		if len(actual) != len(c.expected) {
+			t.Error("unexpected days", c.spec, c.month, actual, c.expected)
+			continue
+		}
+
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Error("unexpected days", c.spec, c.month, actual, c.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestDayRulesInvalid(t *testing.T) {
+	for _, spec := range []string{"0 0 32W * * command", "0 0 L-31 * * command", "0 0 * * 8L command", "0 0 * * 2#6 command"} {
+		if _, err := specparser.NewTaskSpec(spec); err == nil {
+			t.Error("expecting error none found", spec)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+)
+
+var describeCases = []struct {
+	spec        string
+	mode        specparser.ParseMode
+	description string
+}{
+	{"1-15,42-46 9-17 * * 1-5", specparser.ParseStandard, "At minutes 1 through 15 and 42 through 46 past every hour from 09:00 to 17:59, Monday through Friday"},
+	{"* * * * *", specparser.ParseStandard, "Every minute"},
+	{"*/15 * * * *", specparser.ParseStandard, "Every 15 minutes"},
+	{"*/15 9-17 * * *", specparser.ParseStandard, "Every 15 minutes from 09:00 to 17:59"},
+	{"5/20 * * * *", specparser.ParseStandard, "Every 20 minutes starting at minute 5"},
+	{"30 2 * * *", specparser.ParseStandard, "At 02:30"},
+	{"0 9,17 * * MON,WED,FRI", specparser.ParseStandard, "At 09:00 and 17:00, Monday, Wednesday and Friday"},
+	{"5 */2 * * *", specparser.ParseStandard, "At minute 5 past every 2 hours"},
+	{"0 0-5,12 * * SAT,SUN", specparser.ParseStandard, "At minute 0 past every hour from 00:00 to 05:59 and 12:00 to 12:59, Saturday and Sunday"},
+	{"0 0 1,15 * *", specparser.ParseStandard, "At 00:00, on days 1 and 15 of the month"},
+	{"15 10 L * *", specparser.ParseStandard, "At 10:15, on the last day of the month"},
+	{"0 0 L-3 * *", specparser.ParseStandard, "At 00:00, on the last day of the month minus 3 days"},
+	{"0 12 15W * *", specparser.ParseStandard, "At 12:00, on the weekday nearest day 15 of the month"},
+	{"0 18 LW * *", specparser.ParseStandard, "At 18:00, on the last weekday of the month"},
+	{"0 6 * * FRI#2", specparser.ParseStandard, "At 06:00, on the second Friday of the month"},
+	{"45 23 * * 0L", specparser.ParseStandard, "At 23:45, on the last Sunday of the month"},
+	{"0 0 1 1,7 *", specparser.ParseStandard, "At 00:00, on day 1 of the month, in January and July"},
+	{"5 4 * */3 *", specparser.ParseStandard, "At 04:05, every 3 months"},
+	{"0 0 1 * MON", specparser.ParseStandard, "At 00:00, on day 1 of the month or on Monday"},
+	{"0 0 L * 5L", specparser.ParseStandard, "At 00:00, on the last day of the month or on the last Friday of the month"},
+	{"0 0 1 * MON", specparser.ParseStrictDays, "At 00:00, on day 1 of the month, Monday"},
+	{"@weekly", specparser.ParseStandard, "At 00:00, Sunday"},
+	{"@reboot", specparser.ParseStandard, "At startup"},
+	{"*/20 * * * * *", specparser.ParseSeconds, "Every 20 seconds"},
+	{"10 0 12 * * *", specparser.ParseSeconds, "At 12:00:10"},
+	{"15,45 * 9 * * *", specparser.ParseSeconds, "At seconds 15 and 45 of every minute from 09:00 to 09:59"},
+	{"0-29 * * * * *", specparser.ParseSeconds, "At seconds 0 through 29 of every minute"},
+	{"30 * */2 * * *", specparser.ParseSeconds, "At second 30 of every minute of every 2 hours"},
+	{"*/10 * 9-17 * * *", specparser.ParseSeconds, "Every 10 seconds from 09:00 to 17:59"},
+	{"15,45 */5 * * * *", specparser.ParseSeconds, "At seconds 15 and 45 of every 5 minutes past every hour"},
+	{"0 30 8 ? * 2#1 2026-2028", specparser.ParseQuartz, "At 08:30, on the first Monday of the month, in 2026 through 2028"},
+}
+
+func TestTaskSpec_Describe(t *testing.T) {
+	for _, c := range describeCases {
+		taskSpec, err := specparser.NewTaskSpecMode(c.spec+" command", c.mode)
+
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+
+		if description := taskSpec.Describe(); description != c.description {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.description, description)
+		}
+	}
+}
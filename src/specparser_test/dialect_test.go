@@ -0,0 +1,174 @@
+package specparser_test
+
+import (
+	"errors"
+	"specparser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupDialect(t *testing.T) {
+	for _, name := range []string{"vixie", "quartz", "aws", "kubernetes", "jenkins", "Quartz"} {
+		if _, ok := specparser.LookupDialect(name); !ok {
+			t.Error("missing dialect", name)
+		}
+	}
+
+	if _, ok := specparser.LookupDialect("systemd"); ok {
+		t.Error("unexpected dialect")
+	}
+
+	specparser.RegisterDialect(&specparser.Dialect{Name: "Busybox", Mode: specparser.ParseStandard})
+
+	if dialect, ok := specparser.LookupDialect("busybox"); !ok || dialect.Name != "Busybox" {
+		t.Error("registered dialect not found")
+	}
+}
+
+func TestNewTaskSpec_Dialect(t *testing.T) {
+	var cases = []struct {
+		spec    string
+		dialect *specparser.Dialect
+		field   string
+		token   string
+		reason  specparser.ParseErrorReason
+	}{
+		{"0 0 L * * cmd", specparser.DialectVixie, "day", "L", specparser.ReasonInvalidRule},
+		{"0 0 * * 5#2 cmd", specparser.DialectKubernetes, "day-of-week", "5#2", specparser.ReasonInvalidRule},
+		{"H * * * * cmd", specparser.DialectVixie, "minute", "H", specparser.ReasonInvalidValue},
+		{"@reboot cmd", specparser.DialectKubernetes, "", "@reboot", specparser.ReasonUnknownMacro},
+		{"@daily cmd", specparser.DialectQuartz, "", "@daily", specparser.ReasonUnknownMacro},
+		{"0 0 12 * * MON cmd", specparser.DialectQuartz, "day-of-week", "MON", specparser.ReasonInvalidValue},
+		{"0 12 * * ? cmd", specparser.DialectAWS, "", "", specparser.ReasonFieldCount},
+		{"0 12 * * ? cmd", specparser.DialectVixie, "day-of-week", "?", specparser.ReasonInvalidValue},
+		{"5/10 * * * * cmd", specparser.DialectVixie, "minute", "5/10", specparser.ReasonInvalidStep},
+		{"0 0 1,10/5 * * cmd", specparser.DialectJenkins, "day", "1,10/5", specparser.ReasonInvalidStep},
+	}
+
+	for _, c := range cases {
+		_, err := specparser.NewTaskSpec(c.spec, c.dialect)
+
+		var parseError *specparser.ParseError
+
+		if !errors.As(err, &parseError) {
+			t.Error("expecting ParseError", c.spec, err)
+			continue
+		}
+
+		if parseError.Field != c.field || parseError.Token != c.token || parseError.Reason != c.reason {
+			t.Errorf("unexpected error for %q in %s: %+v", c.spec, c.dialect.Name, parseError)
+		}
+	}
+
+	for _, spec := range []string{"5-59/10 */2 * * * cmd", "0 12 ? * MON cmd", "5/10 * * * * cmd"} {
+		if _, err := specparser.NewTaskSpecDialect(spec, specparser.DialectKubernetes); err != nil {
+			t.Error("unexpected error", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"H H * * * cmd", "H/10 H(0-5) * * * cmd", "@weekly cmd"} {
+		if _, err := specparser.NewTaskSpecDialect(spec, specparser.DialectJenkins); err != nil {
+			t.Error("unexpected error", spec, err)
+		}
+	}
+}
+
+func TestTaskSpec_HasDayOfWeekDialect(t *testing.T) {
+	quartz, err := specparser.NewTaskSpecDialect("0 0 12 ? * SUN cmd", specparser.DialectQuartz)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !quartz.HasDayOfWeek(1) || quartz.HasDayOfWeek(7) || quartz.HasDayOfWeek(0) {
+		t.Error("quartz numbers sunday as 1")
+	}
+
+	// A spec read by mode numbers its days as the mode does
+	byMode, _ := specparser.NewTaskSpecMode("0 0 12 ? * 1 cmd", specparser.ParseQuartz)
+
+	if !byMode.HasDayOfWeek(1) || byMode.HasDayOfWeek(7) {
+		t.Error("expecting the quartz numbering from the mode")
+	}
+
+	if again, _ := specparser.NewTaskSpecMode("0 0 12 ? * 2 cmd", specparser.ParseQuartz); again.Dialect != byMode.Dialect {
+		t.Error("expecting specs read by the same mode to share a dialect")
+	}
+
+	if !quartz.Matches(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expecting a match on a sunday")
+	}
+
+	vixie, _ := specparser.NewTaskSpecDialect("0 12 * * SUN cmd", specparser.DialectVixie)
+
+	if !vixie.HasDayOfWeek(0) || !vixie.HasDayOfWeek(7) || vixie.HasDayOfWeek(1) {
+		t.Error("vixie numbers sunday as 0 or 7")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var cases = []struct {
+		expression string
+		from, to   *specparser.Dialect
+		result     string
+	}{
+		{"0 12 * * 1-5", specparser.DialectVixie, specparser.DialectQuartz, "0 0 12 ? * 2-6"},
+		{"0 0 12 ? * MON-FRI", specparser.DialectQuartz, specparser.DialectVixie, "0 12 * * 1-5"},
+		{"0 12 1 * *", specparser.DialectVixie, specparser.DialectAWS, "0 12 1 * ? *"},
+		{"0 12 ? * 1 2027", specparser.DialectAWS, specparser.DialectQuartz, "0 0 12 ? * 1 2027"},
+		{"@weekly", specparser.DialectVixie, specparser.DialectAWS, "0 0 ? * 1 *"},
+		{"*/15 * * * *", specparser.DialectKubernetes, specparser.DialectJenkins, "*/15 * * * *"},
+		{"0 0 1 * 1", specparser.DialectJenkins, specparser.DialectJenkins, "0 0 1 * 1"},
+		{"0 0 */2 * 1", specparser.DialectVixie, specparser.DialectJenkins, "0 0 */2 * 1"},
+		{"0 30 8 ? * 6L", specparser.DialectQuartz, specparser.DialectAWS, "30 8 ? * 6L *"},
+		{"@reboot", specparser.DialectVixie, specparser.DialectVixie, "@reboot"},
+		{"0 5/10 * * * ?", specparser.DialectQuartz, specparser.DialectVixie, "5-59/10 * * * *"},
+		{"0 0 * * */2", specparser.DialectVixie, specparser.DialectQuartz, "0 0 0 ? * 1-7/2"},
+		{"0 0 0 ? * */3", specparser.DialectQuartz, specparser.DialectVixie, "0 0 * * */3"},
+	}
+
+	for _, c := range cases {
+		result, err := specparser.Convert(c.expression, c.from, c.to)
+
+		if err != nil || result != c.result {
+			t.Errorf("%s from %s to %s: expected %q, got %q %v", c.expression, c.from.Name, c.to.Name, c.result, result, err)
+		}
+	}
+}
+
+func TestConvertImpossible(t *testing.T) {
+	var cases = []struct {
+		expression string
+		from, to   *specparser.Dialect
+		field      string
+		message    string
+	}{
+		{"0 0 1 * 1", specparser.DialectVixie, specparser.DialectJenkins, "day-of-week", "either"},
+		{"0 0 1 * 1", specparser.DialectJenkins, specparser.DialectVixie, "day-of-week", "both"},
+		{"0 0 1 * 1", specparser.DialectVixie, specparser.DialectQuartz, "day-of-week", "?"},
+		{"0 0 0 L * ?", specparser.DialectQuartz, specparser.DialectVixie, "", "not supported"},
+		{"30 0 12 * * ?", specparser.DialectQuartz, specparser.DialectVixie, "second", "seconds"},
+		{"0 0 12 * * ? 2027", specparser.DialectQuartz, specparser.DialectKubernetes, "year", "year"},
+		{"@reboot", specparser.DialectVixie, specparser.DialectKubernetes, "", "@reboot"},
+	}
+
+	for _, c := range cases {
+		_, err := specparser.Convert(c.expression, c.from, c.to)
+
+		var conversionError *specparser.ConversionError
+
+		if !errors.As(err, &conversionError) {
+			t.Error("expecting ConversionError", c.expression, err)
+			continue
+		}
+
+		if conversionError.Field != c.field || !strings.Contains(conversionError.Message, c.message) {
+			t.Errorf("unexpected error for %s from %s to %s: %v", c.expression, c.from.Name, c.to.Name, err)
+		}
+	}
+
+	if _, err := specparser.Convert("0 0 * * 8", specparser.DialectVixie, specparser.DialectQuartz); err == nil {
+		t.Error("expecting a parse error")
+	}
+}
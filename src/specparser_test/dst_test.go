@@ -0,0 +1,150 @@
+package specparser_test
+
+import (
+	"specparser"
+	"testing"
+	"time"
+)
+
+var dstCases = []struct {
+	name    string
+	zone    string
+	spec    string
+	start   time.Time // UTC
+	minutes int
+	vixie   []string // UTC clock times of the fires, HH:MM
+	literal []string
+}{
+	// New York springs forward at 02:00 EST (07:00 UTC) to 03:00 EDT on 8 March 2026
+	{"fixed in gap", "America/New_York", "30 2 * * *", utc(2026, 3, 8, 6, 0), 120, []string{"07:00"}, nil},
+	{"fixed after gap", "America/New_York", "0 3 * * *", utc(2026, 3, 8, 6, 0), 120, []string{"07:00"}, []string{"07:00"}},
+	{"fixed before gap", "America/New_York", "59 1 * * *", utc(2026, 3, 8, 6, 0), 120, []string{"06:59"}, []string{"06:59"}},
+	{"hourly in gap", "America/New_York", "15 * * * *", utc(2026, 3, 8, 6, 0), 120, []string{"06:15", "07:15"}, []string{"06:15", "07:15"}},
+	{"stepped in gap", "America/New_York", "*/30 2,3 * * *", utc(2026, 3, 8, 6, 0), 120, []string{"07:00", "07:30"}, []string{"07:00", "07:30"}},
+	{"list in gap", "America/New_York", "10,40 2 * * *", utc(2026, 3, 8, 6, 0), 120, []string{"07:00"}, nil},
+
+	// New York falls back at 02:00 EDT (06:00 UTC) to 01:00 EST on 1 November 2026
+	{"fixed in overlap", "America/New_York", "30 1 * * *", utc(2026, 11, 1, 4, 0), 240, []string{"05:30"}, []string{"05:30", "06:30"}},
+	{"fixed after overlap", "America/New_York", "30 2 * * *", utc(2026, 11, 1, 4, 0), 240, []string{"07:30"}, []string{"07:30"}},
+	{"wildcard in overlap", "America/New_York", "*/30 1 * * *", utc(2026, 11, 1, 4, 0), 240, []string{"05:00", "05:30", "06:00", "06:30"}, []string{"05:00", "05:30", "06:00", "06:30"}},
+	{"hourly in overlap", "America/New_York", "45 * * * *", utc(2026, 11, 1, 4, 0), 240, []string{"04:45", "05:45", "06:45", "07:45"}, []string{"04:45", "05:45", "06:45", "07:45"}},
+
+	// London springs forward at 01:00 GMT to 02:00 BST on 29 March 2026 and falls back at 02:00 BST on 25 October 2026
+	{"london gap", "Europe/London", "30 1 * * *", utc(2026, 3, 29, 0, 0), 120, []string{"01:00"}, nil},
+	{"london overlap", "Europe/London", "15 1 * * *", utc(2026, 10, 24, 23, 30), 180, []string{"00:15"}, []string{"00:15", "01:15"}},
+
+	// Tokyo has no daylight saving, both policies agree
+	{"no transition", "Asia/Tokyo", "30 2 * * *", utc(2026, 3, 7, 17, 0), 120, []string{"17:30"}, []string{"17:30"}},
+}
+
+func utc(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func clockTimes(schedule specparser.Schedule) (times []string) {
+	for i := range schedule {
+		times = append(times, schedule[i].UTC().Format("15:04"))
+	}
+
+	return times
+}
+
+func sameTimes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestNewTaskList_DaylightSaving(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	for _, c := range dstCases {
+		taskSpec, err := specparser.NewTaskSpec(c.spec + " command")
+
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+
+		if taskSpec.Location, err = specparser.LoadLocation(c.zone); err != nil {
+			t.Fatal(c.name, err)
+		}
+
+		for _, policy := range []specparser.DSTPolicy{specparser.DSTVixie, specparser.DSTLiteral} {
+			expected := c.vixie
+
+			if policy == specparser.DSTLiteral {
+				expected = c.literal
+			}
+
+			taskSpec.DST = policy
+			taskList, _ := specparser.NewTaskList(taskSpec, c.start, c.minutes)
+
+			if actual := clockTimes(taskList.Schedule); !sameTimes(actual, expected) {
+				t.Error(c.name, c.spec, "policy", policy, "expected", expected, "got", actual)
+			}
+		}
+	}
+}
+
+// Next and Prev walked across the window give the same fire times as NewTaskList under either policy
+func TestTaskSpec_NextDaylightSaving(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	for _, c := range dstCases {
+		taskSpec, _ := specparser.NewTaskSpec(c.spec + " command")
+		taskSpec.Location, _ = specparser.LoadLocation(c.zone)
+		end := c.start.Add(time.Duration(c.minutes) * time.Minute)
+
+		for _, policy := range []specparser.DSTPolicy{specparser.DSTVixie, specparser.DSTLiteral} {
+			taskSpec.DST = policy
+			taskList, _ := specparser.NewTaskList(taskSpec, c.start, c.minutes)
+			expected := clockTimes(taskList.Schedule)
+
+			var forward, backward specparser.Schedule
+
+			for at, err := taskSpec.Next(c.start.Add(-time.Nanosecond)); err == nil && at.Before(end); at, err = taskSpec.Next(at) {
+				forward = append(forward, at)
+			}
+
+			for at, err := taskSpec.Prev(end); err == nil && !at.Before(c.start); at, err = taskSpec.Prev(at) {
+				backward = append(specparser.Schedule{at}, backward...)
+			}
+
+			if actual := clockTimes(forward); !sameTimes(actual, expected) {
+				t.Error(c.name, c.spec, "policy", policy, "Next expected", expected, "got", actual)
+			}
+
+			if actual := clockTimes(backward); !sameTimes(actual, expected) {
+				t.Error(c.name, c.spec, "policy", policy, "Prev expected", expected, "got", actual)
+			}
+		}
+	}
+}
+
+func TestNewTaskList_DaylightSavingSeconds(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	taskSpec, _ := specparser.NewTaskSpecMode("10 30 2 * * * command", specparser.ParseSeconds)
+	taskSpec.Location, _ = specparser.LoadLocation("America/New_York")
+	taskList, _ := specparser.NewTaskList(taskSpec, utc(2026, 3, 8, 6, 58), 4)
+
+	if len(taskList.Schedule) != 1 || !taskList.Schedule[0].Equal(utc(2026, 3, 8, 7, 0)) {
+		t.Error("expecting the skipped job on the first second after the gap", taskList.Schedule)
+	}
+
+	if next, _ := taskSpec.Next(utc(2026, 3, 8, 6, 58)); !next.Equal(utc(2026, 3, 8, 7, 0)) {
+		t.Error("expecting Next to give the skipped job on the first second after the gap", next)
+	}
+}
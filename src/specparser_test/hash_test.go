@@ -0,0 +1,150 @@
+package specparser_test
+
+import (
+	"errors"
+	"fmt"
+	"specparser"
+	"testing"
+)
+
+func TestTaskSpec_NewHashed(t *testing.T) {
+	first, err := specparser.NewTaskSpec("H H * * * /scripts/backup.sh")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := specparser.NewTaskSpec("H   H * * *   /scripts/backup.sh")
+
+	if first.Schedule.String() != again.Schedule.String() {
+		t.Error("the same job should always resolve to the same values", first.Schedule.String(), again.Schedule.String())
+	}
+
+	if first.Schedule.Minutes.Count() != 1 || first.Schedule.Hours.Count() != 1 || first.Schedule.MinuteStar || first.Expression != "H H * * *" {
+		t.Error("H should resolve to a single value", first.Schedule.String())
+	}
+}
+
+func TestTaskSpec_NewHashedSpread(t *testing.T) {
+	minutes := make(map[int]bool)
+
+	for i := 0; i < 400; i++ {
+		taskSpec, err := specparser.NewTaskSpec(fmt.Sprintf("H * * * * /scripts/job%d.sh", i))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		minutes[taskSpec.Schedule.Minutes.Values()[0]] = true
+	}
+
+	if len(minutes) < 50 {
+		t.Error("expecting the jobs spread over the hour, got minutes", len(minutes))
+	}
+}
+
+func TestTaskSpec_NewHashedUsers(t *testing.T) {
+	times := make(map[string]bool)
+
+	for i := 0; i < 20; i++ {
+		taskSpec, err := specparser.NewTaskSpecMode(fmt.Sprintf("H H * * * user%d /scripts/backup.sh", i), specparser.ParseSystem)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		times[taskSpec.Schedule.String()] = true
+	}
+
+	if len(times) < 2 {
+		t.Error("the same command run by different users should not share its H values")
+	}
+}
+
+func TestTaskSpec_NewHashedForms(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		command := fmt.Sprintf("job%d", i)
+
+		ranged, err := specparser.NewTaskSpec("H(0-29) * H * H(MON-FRI) " + command)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if minute := ranged.Schedule.Minutes.Values()[0]; minute > 29 {
+			t.Error("minute outside H(0-29)", minute)
+		}
+
+		if days := ranged.Schedule.Days.Values(); len(days) != 1 || days[0] > 28 {
+			t.Error("day of month should be within 1-28", days)
+		}
+
+		if dayOfWeek := ranged.Schedule.DaysOfWeek.Values(); len(dayOfWeek) != 1 || dayOfWeek[0] > 5 {
+			t.Error("expecting a weekday for H(MON-FRI)", dayOfWeek)
+		}
+
+		stepped, err := specparser.NewTaskSpec("H/15 H(9-17)/4 * * * " + command)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		minutes := stepped.Schedule.Minutes.Values()
+
+		if len(minutes) != 4 || minutes[0] >= 15 || minutes[1]-minutes[0] != 15 {
+			t.Error("unexpected minutes for H/15", minutes)
+		}
+
+		if hours := stepped.Schedule.Hours.Values(); hours[0] < 9 || hours[0] > 12 || hours[len(hours)-1] > 17 {
+			t.Error("unexpected hours for H(9-17)/4", hours)
+		}
+	}
+}
+
+func TestTaskSpec_NewHashedQuartz(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		taskSpec, err := specparser.NewTaskSpecMode(fmt.Sprintf("H H 12 ? * H(MON-FRI) job%d", i), specparser.ParseQuartz)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if dayOfWeek := taskSpec.Schedule.DaysOfWeek.Values()[0]; dayOfWeek < 1 || dayOfWeek > 5 {
+			t.Error("expecting a weekday", dayOfWeek)
+		}
+	}
+}
+
+func TestTaskSpec_NewHashedErrors(t *testing.T) {
+	var cases = []struct {
+		spec   string
+		field  string
+		offset int
+		token  string
+		reason specparser.ParseErrorReason
+	}{
+		{"H(30-70) * * * * cmd", "minute", 5, "70", specparser.ReasonOutOfRange},
+		{"0 H(x) * * * cmd", "hour", 2, "H(x)", specparser.ReasonInvalidValue},
+		{"H/0 * * * * cmd", "minute", 2, "0", specparser.ReasonInvalidStep},
+		{"H/90 * * * * cmd", "minute", 2, "90", specparser.ReasonOutOfRange},
+		{"0 H(9-17)/10 * * * cmd", "hour", 10, "10", specparser.ReasonOutOfRange},
+		{"0 0 1,H/30 * * cmd", "day", 8, "30", specparser.ReasonOutOfRange},
+		{"0 H,5,x * * * cmd", "hour", 6, "x", specparser.ReasonInvalidValue},
+		{"0 0 * * H(MON-XYZ) cmd", "day-of-week", 8, "H(MON-XYZ)", specparser.ReasonInvalidValue},
+	}
+
+	for _, c := range cases {
+		_, err := specparser.NewTaskSpec(c.spec)
+
+		var parseError *specparser.ParseError
+
+		if !errors.As(err, &parseError) {
+			t.Error("expecting ParseError", c.spec, err)
+			continue
+		}
+
+		if parseError.Field != c.field || parseError.Offset != c.offset || parseError.Token != c.token || parseError.Reason != c.reason {
+			t.Errorf("unexpected error for %q: %+v", c.spec, parseError)
+		}
+	}
+}
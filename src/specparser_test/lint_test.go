@@ -0,0 +1,72 @@
+package specparser_test
+
+import (
+	"specparser"
+	"strings"
+	"testing"
+)
+
+func lintCodes(t *testing.T, spec string) (codes []string) {
+	taskSpec, err := specparser.NewTaskSpec(spec + " command")
+
+	if err != nil {
+		t.Fatal(spec, err)
+	}
+
+	for _, finding := range specparser.Lint(taskSpec) {
+		codes = append(codes, string(finding.Code))
+	}
+
+	return codes
+}
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		spec  string
+		codes string
+	}{
+		{"0 2 * * *", ""},
+		{"*/15 9-17 * * 1-5", ""},
+		{"@daily", ""},
+		{"@reboot", ""},
+		{"* 2 * * *", "every-minute-in-hour"},
+		{"* * * * *", ""},
+		{"0 0 31 2,4,6 *", "never-fires"},
+		{"0 0 30 2 *", "never-fires"},
+		{"0 0 1,30 2 *", "unreachable-day"},
+		{"0 0 31 * *", ""},
+		{"0 0 13 * 5", "day-and-weekday"},
+		{"0 0 13 * */1", ""},
+		{"0 0 ? * 5", ""},
+		{"*/7 * * * *", "uneven-step"},
+		{"5/20 * * * *", ""},
+		{"0 */5 * * *", "uneven-step"},
+		{"0 0 */2 * *", "uneven-step"},
+		{"0 0 1 */5 *", "uneven-step"},
+		{"0 0 * * */2", "uneven-step"},
+		{"0-30/7 * * * *", ""},
+	}
+
+	for _, c := range cases {
+		if codes := strings.Join(lintCodes(t, c.spec), ","); codes != c.codes {
+			t.Errorf("%s: expected %q, got %q", c.spec, c.codes, codes)
+		}
+	}
+}
+
+func TestCrontab_Lint(t *testing.T) {
+	crontab, _ := specparser.ParseCrontab(strings.NewReader("0 2 * * * fine\n\n* 2 * * * noisy\n0 0 30 2 * never\n"), specparser.ParseStandard)
+	findings := crontab.Lint()
+
+	if len(findings) != 2 || findings[0].Line != 3 || findings[1].Line != 4 {
+		t.Fatal("unexpected findings", findings)
+	}
+
+	if findings[0].Severity != specparser.LintWarning || findings[1].Severity != specparser.LintError {
+		t.Error("unexpected severities", findings)
+	}
+
+	if message := findings[1].String(); !strings.HasPrefix(message, "line 4: error never-fires: ") {
+		t.Error("unexpected message", message)
+	}
+}
@@ -0,0 +1,151 @@
+package specparser_test
+
+import (
+	"math/rand"
+	"specparser"
+	"testing"
+	"time"
+)
+
+var nextSpecs = []struct {
+	spec string
+	mode specparser.ParseMode
+}{
+	{"* * * * * command", specparser.ParseStandard},
+	{"1-15,42-46,55,57,59 * * * * command", specparser.ParseStandard},
+	{"*/7 9-17 * * MON-FRI command", specparser.ParseStandard},
+	{"30 2 * * * command", specparser.ParseStandard},
+	{"0 0 1,15 * * command", specparser.ParseStandard},
+	{"15 10 L * * command", specparser.ParseStandard},
+	{"0 12 15W * * command", specparser.ParseStandard},
+	{"0 6 * * FRI#2 command", specparser.ParseStandard},
+	{"45 23 * * 0L command", specparser.ParseStandard},
+	{"0 0 31 * * command", specparser.ParseStandard},
+	{"5 4 * */2 * command", specparser.ParseStandard},
+	{"0 0 1,15 * MON command", specparser.ParseStandard},
+	{"0 0 13 * FRI command", specparser.ParseStrictDays},
+	{"30 6 L * 1#2 command", specparser.ParseStandard},
+	{"*/20 0 0 * * * command", specparser.ParseSeconds},
+	{"0 30 8 ? * 2#1 2026-2027 command", specparser.ParseQuartz},
+}
+
+// bruteNext steps through every slot after t, as NewTaskList does, up to the given number of days
+func bruteNext(taskSpec specparser.TaskSpec, t time.Time, step time.Duration, days int) (time.Time, bool) {
+	end := t.AddDate(0, 0, days)
+
+	for t = t.Truncate(step).Add(step); t.Before(end); t = t.Add(step) {
+		if taskSpec.Matches(t) {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func brutePrev(taskSpec specparser.TaskSpec, t time.Time, step time.Duration, days int) (time.Time, bool) {
+	end := t.AddDate(0, 0, -days)
+
+	for t = t.Add(-1).Truncate(step); t.After(end); t = t.Add(-step) {
+		if taskSpec.Matches(t) {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func TestTaskSpec_NextMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, c := range nextSpecs {
+		taskSpec, err := specparser.NewTaskSpecMode(c.spec, c.mode)
+
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+
+		step := time.Minute
+
+		if taskSpec.Schedule.HasSeconds() {
+			step = time.Second
+		}
+
+		for i := 0; i < 6; i++ {
+			start := base.Add(time.Duration(random.Int63n(int64(365 * 24 * time.Hour))))
+
+			if expected, ok := bruteNext(taskSpec, start, step, 35); ok {
+				actual, err := taskSpec.Next(start)
+
+				if err != nil || !actual.Equal(expected) {
+					t.Error("Next disagrees with brute force", c.spec, start, actual, expected, err)
+				}
+			}
+
+			if expected, ok := brutePrev(taskSpec, start, step, 35); ok {
+				actual, err := taskSpec.Prev(start)
+
+				if err != nil || !actual.Equal(expected) {
+					t.Error("Prev disagrees with brute force", c.spec, start, actual, expected, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTaskSpec_NextIsStrictlyAfter(t *testing.T) {
+	taskSpec, _ := specparser.NewTaskSpec("30 2 * * * command")
+	start := time.Date(2026, 3, 10, 2, 30, 0, 0, time.UTC)
+
+	next, _ := taskSpec.Next(start)
+	prev, _ := taskSpec.Prev(start)
+
+	if !next.Equal(start.AddDate(0, 0, 1)) || !prev.Equal(start.AddDate(0, 0, -1)) {
+		t.Error("Next and Prev should exclude the start time", next, prev)
+	}
+}
+
+func TestTaskSpec_NextLeapDay(t *testing.T) {
+	taskSpec, _ := specparser.NewTaskSpec("0 0 29 2 * command")
+
+	next, err := taskSpec.Next(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !next.Equal(time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected next leap day", next, err)
+	}
+
+	prev, err := taskSpec.Prev(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !prev.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected previous leap day", prev, err)
+	}
+}
+
+func TestTaskSpec_NextNeverFires(t *testing.T) {
+	for _, spec := range []string{"0 0 30 2 * command", "0 0 31 4,6,9,11 * command", "@reboot command"} {
+		taskSpec, _ := specparser.NewTaskSpec(spec)
+
+		if _, err := taskSpec.Next(time.Now()); err != specparser.ErrNeverFires {
+			t.Error("expecting ErrNeverFires from Next", spec, err)
+		}
+
+		if _, err := taskSpec.Prev(time.Now()); err != specparser.ErrNeverFires {
+			t.Error("expecting ErrNeverFires from Prev", spec, err)
+		}
+	}
+
+	taskSpec, _ := specparser.NewTaskSpecMode("0 0 0 1 1 ? 2020 command", specparser.ParseQuartz)
+
+	if _, err := taskSpec.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); err != specparser.ErrNeverFires {
+		t.Error("expecting ErrNeverFires after the last year", err)
+	}
+}
+
+func BenchmarkTaskSpec_NextLeapDay(b *testing.B) {
+	taskSpec, _ := specparser.NewTaskSpec("0 0 29 2 * command")
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		taskSpec.Next(start)
+	}
+}
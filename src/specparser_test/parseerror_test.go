@@ -0,0 +1,76 @@
+package specparser_test
+
+import (
+	"errors"
+	"specparser"
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	var cases = []struct {
+		spec   string
+		mode   specparser.ParseMode
+		field  string
+		offset int
+		token  string
+		reason specparser.ParseErrorReason
+	}{
+		{"99x * * * * cmd", specparser.ParseStandard, "minute", 0, "99x", specparser.ReasonInvalidValue},
+		{"  5-x * * * * cmd", specparser.ParseStandard, "minute", 4, "x", specparser.ReasonInvalidValue},
+		{"0 1,2,x * * * cmd", specparser.ParseStandard, "hour", 6, "x", specparser.ReasonInvalidValue},
+		{"0 0 * JAN-FOO * cmd", specparser.ParseStandard, "month", 10, "FOO", specparser.ReasonInvalidValue},
+		{"0 0 * 1-2-3 * cmd", specparser.ParseStandard, "month", 6, "1-2-3", specparser.ReasonInvalidValue},
+		{"-5 * * * * cmd", specparser.ParseStandard, "minute", 0, "-5", specparser.ReasonInvalidValue},
+		{"5- * * * * cmd", specparser.ParseStandard, "minute", 0, "5-", specparser.ReasonInvalidValue},
+		{"0 */ * * * cmd", specparser.ParseStandard, "hour", 2, "*/", specparser.ReasonInvalidValue},
+		{"1,,2 * * * * cmd", specparser.ParseStandard, "minute", 2, "", specparser.ReasonInvalidValue},
+		{"0 0 * * MON, cmd", specparser.ParseStandard, "day-of-week", 12, "", specparser.ReasonInvalidValue},
+		{"*/0 * * * * cmd", specparser.ParseStandard, "minute", 2, "0", specparser.ReasonInvalidStep},
+		{"0 0 1,LX * * cmd", specparser.ParseStandard, "day", 6, "LX", specparser.ReasonInvalidValue},
+		{"0 0 * * MON#9 cmd", specparser.ParseStandard, "day-of-week", 8, "MON#9", specparser.ReasonInvalidRule},
+		{"0 0 12 ? * MON-XYZ cmd", specparser.ParseQuartz, "day-of-week", 15, "XYZ", specparser.ReasonInvalidValue},
+		{"0 0 12 ? * * 2026/0 cmd", specparser.ParseQuartz, "year", 18, "0", specparser.ReasonInvalidStep},
+		{"* * * * command", specparser.ParseStandard, "", 15, "", specparser.ReasonFieldCount},
+		{"@daily", specparser.ParseStandard, "command", 6, "", specparser.ReasonMissingCommand},
+		{" @often cmd", specparser.ParseStandard, "", 1, "@often", specparser.ReasonUnknownMacro},
+		{"* * * * * " + strings.Repeat("x", 1000), specparser.ParseStandard, "command", 10, "", specparser.ReasonCommandTooLong},
+	}
+
+	for _, c := range cases {
+		_, err := specparser.NewTaskSpecMode(c.spec, c.mode)
+
+		var parseError *specparser.ParseError
+
+		if !errors.As(err, &parseError) {
+			t.Error("expecting ParseError", c.spec, err)
+			continue
+		}
+
+		if parseError.Field != c.field || parseError.Offset != c.offset || parseError.Token != c.token || parseError.Reason != c.reason {
+			t.Errorf("unexpected error for %q: %+v", c.spec, parseError)
+		}
+	}
+}
+
+func TestParseErrorFromExpand(t *testing.T) {
+	_, err := specparser.ValueExpression("1,5-7,9-x").Expand(specparser.TimeUnitHours)
+
+	var parseError *specparser.ParseError
+
+	if !errors.As(err, &parseError) {
+		t.Fatal("expecting ParseError", err)
+	}
+
+	if parseError.Field != "hour" || parseError.Offset != 8 || parseError.Token != "x" {
+		t.Errorf("unexpected error %+v", parseError)
+	}
+
+	if err.Error() != `invalid value "x" in hour field at offset 8` {
+		t.Error("unexpected message", err.Error())
+	}
+
+	if _, err = specparser.ValueExpression("1,,2").Expand(specparser.TimeUnitHours); err == nil || err.Error() != "empty list item in hour field at offset 2" {
+		t.Error("unexpected message for an empty item", err)
+	}
+}
@@ -14,3 +14,47 @@ func TestNewTaskList(t *testing.T) {
 		t.Error("failed initialization")
 	}
 }
+
+func TestNewTaskListSeconds(t *testing.T) {
+	spec, err := specparser.NewTaskSpecMode("*/15 * * * * * command", specparser.ParseSeconds)
+	start := time.Date(2017, 6, 1, 12, 0, 7, 500, time.UTC)
+	taskList, err := specparser.NewTaskList(spec, start, 2)
+
+	if err != nil {
+		t.Error("failed initialization")
+	}
+
+	expected := []time.Time{
+		time.Date(2017, 6, 1, 12, 0, 15, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 0, 30, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 0, 45, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 1, 0, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 1, 15, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 1, 30, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 1, 45, 0, time.UTC),
+		time.Date(2017, 6, 1, 12, 2, 0, 0, time.UTC),
+	}
+
+	if len(taskList.Schedule) != len(expected) {
+		t.Fatal("unexpected schedule", taskList.Schedule)
+	}
+
+	for i := range expected {
+		if !taskList.Schedule[i].Equal(expected[i]) {
+			t.Error("unexpected schedule entry", taskList.Schedule[i], expected[i])
+		}
+	}
+}
+
+func TestNewTaskListYears(t *testing.T) {
+	spec, err := specparser.NewTaskSpecMode("0 * * * * * 2018 command", specparser.ParseQuartz)
+	taskList, err := specparser.NewTaskList(spec, time.Date(2017, 12, 31, 23, 55, 0, 0, time.UTC), 10)
+
+	if err != nil {
+		t.Error("failed initialization")
+	}
+
+	if len(taskList.Schedule) != 5 || taskList.Schedule[0].Year() != 2018 {
+		t.Error("only minutes in 2018 should be scheduled", taskList.Schedule)
+	}
+}
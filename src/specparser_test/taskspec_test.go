@@ -3,14 +3,15 @@ package specparser
 import (
 	"specparser"
 	"testing"
+	"time"
 )
 
 func TestTaskSpec_HasMinute(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			Minutes: []specparser.TimeUnit{
+			Minutes: specparser.NewBits([]specparser.TimeUnit{
 				specparser.Minute(12),
-			},
+			}),
 		},
 	}
 
@@ -26,9 +27,9 @@ func TestTaskSpec_HasMinute(t *testing.T) {
 func TestTaskSpec_HasHour(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			Hours: []specparser.TimeUnit{
+			Hours: specparser.NewBits([]specparser.TimeUnit{
 				specparser.Hour(14),
-			},
+			}),
 		},
 	}
 
@@ -45,9 +46,9 @@ func TestTaskSpec_HasHour(t *testing.T) {
 func TestTaskSpec_HasDay(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			Days: []specparser.TimeUnit{
+			Days: specparser.NewBits([]specparser.TimeUnit{
 				specparser.Day(28),
-			},
+			}),
 		},
 	}
 
@@ -64,9 +65,9 @@ func TestTaskSpec_HasDay(t *testing.T) {
 func TestTaskSpec_HasMonth(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			Months: []specparser.TimeUnit{
+			Months: specparser.NewBits([]specparser.TimeUnit{
 				specparser.Month(9),
-			},
+			}),
 		},
 	}
 
@@ -83,9 +84,9 @@ func TestTaskSpec_HasMonth(t *testing.T) {
 func TestTaskSpec_HasDayOfWeek(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			DaysOfWeek: []specparser.TimeUnit{
+			DaysOfWeek: specparser.NewBits([]specparser.TimeUnit{
 				specparser.DayOfWeek(3),
-			},
+			}),
 		},
 	}
 
@@ -102,9 +103,9 @@ func TestTaskSpec_HasDayOfWeek(t *testing.T) {
 func TestTaskSpec_HasDayOfWeekConvertsZero(t *testing.T) {
 	taskSpec := specparser.TaskSpec{
 		Schedule: specparser.TimeSpecExtended{
-			DaysOfWeek: []specparser.TimeUnit{
+			DaysOfWeek: specparser.NewBits([]specparser.TimeUnit{
 				specparser.DayOfWeek(7),
-			},
+			}),
 		},
 	}
 
@@ -138,3 +139,263 @@ func TestTaskSpec_New(t *testing.T) {
 		t.Error("Invalid value for minutes did not generate error")
 	}
 }
+
+func TestTaskSpec_NewMacro(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpec("@daily /scripts/x.sh --flag")
+
+	if err != nil {
+		t.Error("Macro init failed", err)
+	}
+
+	if taskSpec.Command != "/scripts/x.sh --flag" {
+		t.Error("Unexpected command", taskSpec.Command)
+	}
+
+	if !taskSpec.HasMinute(0) || taskSpec.HasMinute(1) || !taskSpec.HasHour(0) || taskSpec.HasHour(1) || !taskSpec.HasDay(17) {
+		t.Error("@daily should expand to 0 0 * * *", taskSpec.Schedule)
+	}
+
+	taskSpec, err = specparser.NewTaskSpec("@WEEKLY command")
+
+	if err != nil || !taskSpec.HasDayOfWeek(0) || taskSpec.HasDayOfWeek(1) {
+		t.Error("@weekly should run on sunday only", err)
+	}
+
+	taskSpec, err = specparser.NewTaskSpec("@reboot command")
+
+	if err != nil || !taskSpec.Reboot {
+		t.Error("@reboot should be flagged as reboot", err)
+	}
+
+	if taskSpec.HasMinute(0) {
+		t.Error("@reboot should not match any time")
+	}
+
+	_, err = specparser.NewTaskSpec("@daily")
+
+	if err == nil {
+		t.Error("Missing command did not generate error")
+	}
+
+	_, err = specparser.NewTaskSpec("@fortnightly command")
+
+	if err == nil {
+		t.Error("Unknown macro did not generate error")
+	}
+}
+
+func TestTaskSpec_NewSecondsMode(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpecMode("*/15 * * * * * /scripts/probe.sh", specparser.ParseSeconds)
+
+	if err != nil {
+		t.Error("Seconds init failed", err)
+	}
+
+	if taskSpec.Command != "/scripts/probe.sh" || taskSpec.Expression != "*/15 * * * * *" {
+		t.Error("Unexpected split of seconds spec", taskSpec.Expression, taskSpec.Command)
+	}
+
+	if !taskSpec.HasSecond(45) || taskSpec.HasSecond(10) {
+		t.Error("Seconds field not applied", taskSpec.Schedule.Seconds)
+	}
+
+	taskSpec, err = specparser.NewTaskSpec("*/15 * * * * command")
+
+	if err != nil || taskSpec.Schedule.HasSeconds() {
+		t.Error("Standard mode should not have a seconds field", err)
+	}
+
+	_, err = specparser.NewTaskSpecMode("* * * * * command", specparser.ParseSeconds)
+
+	if err == nil {
+		t.Error("Missing TimeSpecPart did not generate error")
+	}
+}
+
+func TestTaskSpec_NewQuartzMode(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpecMode("0 0 12 ? * WED 2026-2028 /scripts/report.sh", specparser.ParseQuartz)
+
+	if err != nil {
+		t.Fatal("Quartz init failed", err)
+	}
+
+	if taskSpec.Command != "/scripts/report.sh" || taskSpec.Expression != "0 0 12 ? * WED 2026-2028" {
+		t.Error("Unexpected split of quartz spec", taskSpec.Expression, taskSpec.Command)
+	}
+
+	if !taskSpec.HasSecond(0) || !taskSpec.HasMinute(0) || !taskSpec.HasHour(12) || !taskSpec.HasDay(17) {
+		t.Error("Quartz time fields not applied", taskSpec.Schedule)
+	}
+
+	if !taskSpec.HasDayOfWeek(4) || taskSpec.HasDayOfWeek(3) {
+		t.Error("WED should be Quartz day 4", taskSpec.Schedule.DaysOfWeek)
+	}
+
+	if !taskSpec.HasYear(2026) || !taskSpec.HasYear(2028) || taskSpec.HasYear(2029) {
+		t.Error("Year field not applied", taskSpec.Schedule.Years)
+	}
+
+	taskSpec, err = specparser.NewTaskSpecMode("0 0 12 ? * 1,4 command", specparser.ParseQuartz)
+
+	if err != nil {
+		t.Fatal("Quartz init without year failed", err)
+	}
+
+	if !taskSpec.Schedule.AnyYear() || !taskSpec.HasYear(1999) {
+		t.Error("Missing year should match any year", taskSpec.Schedule.Years)
+	}
+
+	if !taskSpec.HasDayOfWeek(1) || !taskSpec.HasDayOfWeek(4) || taskSpec.HasDayOfWeek(2) || !taskSpec.Schedule.DaysOfWeek.Has(7) {
+		t.Error("Quartz day of week 1 is sunday and 4 is wednesday", taskSpec.Schedule.DaysOfWeek)
+	}
+}
+
+func TestTaskSpec_NewOutOfRange(t *testing.T) {
+	for _, spec := range []string{"60 * * * * command", "0 25 * * * command", "0 0 0 * * command", "40-60 * * * * command"} {
+		if _, err := specparser.NewTaskSpec(spec); err == nil {
+			t.Error("out of range value did not generate error", spec)
+		}
+	}
+
+	if _, err := specparser.NewTaskSpecMode("0 0 12 ? * 0 command", specparser.ParseQuartz); err == nil {
+		t.Error("quartz day of week 0 did not generate error")
+	}
+
+	if _, err := specparser.NewTaskSpecMode("0 0 12 ? * 1-7/2 command", specparser.ParseQuartz); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestTaskSpec_NewSystemMode(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpecMode("17 * * * * root cd / && run-parts --report /etc/cron.hourly", specparser.ParseSystem)
+
+	if err != nil {
+		t.Fatal("System init failed", err)
+	}
+
+	if taskSpec.User != "root" || taskSpec.Command != "cd / && run-parts --report /etc/cron.hourly" || taskSpec.Expression != "17 * * * *" {
+		t.Error("Unexpected split of system spec", taskSpec.Expression, taskSpec.User, taskSpec.Command)
+	}
+
+	taskSpec, err = specparser.NewTaskSpecMode("@reboot www-data /scripts/warm-cache.sh", specparser.ParseSystem)
+
+	if err != nil || !taskSpec.Reboot || taskSpec.User != "www-data" || taskSpec.Command != "/scripts/warm-cache.sh" {
+		t.Error("Unexpected split of system macro", taskSpec.User, taskSpec.Command, err)
+	}
+
+	taskSpec, err = specparser.NewTaskSpecMode("0 0 12 ? * MON 2027 backup /scripts/report.sh", specparser.ParseQuartz|specparser.ParseSystem)
+
+	if err != nil || taskSpec.User != "backup" || !taskSpec.HasYear(2027) || taskSpec.HasYear(2026) {
+		t.Error("Year should precede the user field", taskSpec.User, taskSpec.Schedule.Years, err)
+	}
+
+	taskSpec, _ = specparser.NewTaskSpec("17 * * * * root command")
+
+	if taskSpec.User != "" || taskSpec.Command != "root command" {
+		t.Error("Standard mode has no user field", taskSpec.User, taskSpec.Command)
+	}
+
+	for _, spec := range []string{"17 * * * * root", "@hourly root"} {
+		if _, err := specparser.NewTaskSpecMode(spec, specparser.ParseSystem); err == nil {
+			t.Error("Missing command did not generate error", spec)
+		}
+	}
+}
+
+func TestTaskSpec_NewPercentStdin(t *testing.T) {
+	cases := []struct {
+		spec    string
+		command string
+		stdin   string
+	}{
+		{"0 6 * * * mail -s report ops%body  text", "mail -s report ops", "body  text\n"},
+		{"0 6 * * * cat%one%two%", "cat", "one\ntwo\n"},
+		{"0 6 * * * date +\\%Y-\\%m-\\%d", "date +%Y-%m-%d", ""},
+		{"0 6 * * * printf x%50\\% done", "printf x", "50% done\n"},
+		{"0 6 * * * backup.sh %", "backup.sh ", ""},
+		{"@daily wall%rebooting", "wall", "rebooting\n"},
+	}
+
+	for _, c := range cases {
+		taskSpec, err := specparser.NewTaskSpec(c.spec)
+
+		if err != nil || taskSpec.Command != c.command || taskSpec.Stdin != c.stdin {
+			t.Errorf("%s: expected %q %q, got %q %q %v", c.spec, c.command, c.stdin, taskSpec.Command, taskSpec.Stdin, err)
+		}
+	}
+}
+
+func TestTaskSpec_MatchDays(t *testing.T) {
+	either, _ := specparser.NewTaskSpec("0 0 1 * MON command")
+	both, _ := specparser.NewTaskSpecMode("0 0 1 * MON command", specparser.ParseStrictDays)
+	starred, _ := specparser.NewTaskSpec("0 0 1 * */1 command")
+
+	cases := []struct {
+		day                   time.Time
+		either, both, starred bool
+	}{
+		{time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), true, true, true},    // The 1st and a Monday
+		{time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), true, false, true},   // The 1st, a Wednesday
+		{time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC), true, false, false},  // A Monday
+		{time.Date(2026, 7, 7, 0, 0, 0, 0, time.UTC), false, false, false}, // Neither
+	}
+
+	for _, c := range cases {
+		if either.MatchDays(c.day) != c.either || both.MatchDays(c.day) != c.both || starred.MatchDays(c.day) != c.starred {
+			t.Error("unexpected match", c.day)
+		}
+
+		if either.Matches(c.day) != c.either || both.Matches(c.day) != c.both {
+			t.Error("Matches should agree with MatchDays", c.day)
+		}
+	}
+}
+
+// NewTimeSpecExtended marks a full day field as starred, so a hand built spec for every day on Mondays runs on Mondays
+func TestNewTimeSpecExtended(t *testing.T) {
+	var days, months []specparser.TimeUnit
+
+	for day := 1; day <= 31; day++ {
+		days = append(days, specparser.Day(day))
+	}
+
+	for month := 1; month <= 12; month++ {
+		months = append(months, specparser.Month(month))
+	}
+
+	midnight := []specparser.TimeUnit{specparser.Minute(0)}
+	mondays := specparser.TaskSpec{
+		Schedule: specparser.NewTimeSpecExtended(midnight, []specparser.TimeUnit{specparser.Hour(0)}, days, months, []specparser.TimeUnit{specparser.DayOfWeek(1)}),
+	}
+
+	if !mondays.Schedule.DayStar || mondays.Schedule.EitherDay() || mondays.Matches(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Error("a Tuesday should not match a spec for every day of the month on Mondays")
+	}
+
+	if !mondays.Matches(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Error("a Monday should match")
+	}
+
+	// Written as 1-31 rather than * the day field stays restricted, as cron reads it either field is then enough
+	written, _ := specparser.NewTaskSpec("0 0 1-31 * MON command")
+
+	if !written.Schedule.EitherDay() || !written.Matches(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Error("1-31 with a day of week should run every day")
+	}
+}
+
+func TestTaskSpec_NewWrapAround(t *testing.T) {
+	taskSpec, err := specparser.NewTaskSpec("0 22-2 * * FRI-MON command")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expanded := taskSpec.Schedule.String(); expanded != "0 0-2,22,23 * * 1,5-7" {
+		t.Error("unexpected expansion", expanded)
+	}
+
+	if !taskSpec.Matches(time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)) || taskSpec.Matches(time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC)) {
+		t.Error("expecting 01:00 on a Monday but not a Tuesday")
+	}
+}
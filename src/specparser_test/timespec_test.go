@@ -111,35 +111,55 @@ func TestTimeExpression_Explode(t *testing.T) {
 	}
 
 	switch {
-	case len(sample.Minutes) != 5:
-	case sample.Minutes[0] != specparser.Minute(1):
-	case sample.Minutes[1] != specparser.Minute(2):
-	case sample.Minutes[2] != specparser.Minute(3):
-	case sample.Minutes[3] != specparser.Minute(4):
-	case sample.Minutes[4] != specparser.Minute(5):
-	case len(sample.Hours) != 3:
-	case sample.Hours[0] != specparser.Hour(1):
-	case sample.Hours[1] != specparser.Hour(3):
-	case sample.Hours[2] != specparser.Hour(4):
-	case len(sample.Days) != 3:
-	case sample.Days[0] != specparser.Day(1):
-	case sample.Days[1] != specparser.Day(3):
-	case sample.Days[2] != specparser.Day(5):
-	case len(sample.Months) != 4:
-	case sample.Months[0] != specparser.Month(1):
-	case sample.Months[1] != specparser.Month(4):
-	case sample.Months[2] != specparser.Month(7):
-	case sample.Months[3] != specparser.Month(10):
-	case len(sample.DaysOfWeek) != 7:
-	case sample.DaysOfWeek[0] != specparser.DayOfWeek(1):
-	case sample.DaysOfWeek[1] != specparser.DayOfWeek(2):
-	case sample.DaysOfWeek[2] != specparser.DayOfWeek(3):
-	case sample.DaysOfWeek[3] != specparser.DayOfWeek(4):
-	case sample.DaysOfWeek[4] != specparser.DayOfWeek(5):
-	case sample.DaysOfWeek[5] != specparser.DayOfWeek(6):
-	case sample.DaysOfWeek[6] != specparser.DayOfWeek(7):
+	case sample.Minutes.Count() != 5:
+	case !sample.Minutes.Has(1):
+	case !sample.Minutes.Has(2):
+	case !sample.Minutes.Has(3):
+	case !sample.Minutes.Has(4):
+	case !sample.Minutes.Has(5):
+	case sample.Hours.Count() != 3:
+	case !sample.Hours.Has(1):
+	case !sample.Hours.Has(3):
+	case !sample.Hours.Has(4):
+	case sample.Days.Count() != 3:
+	case !sample.Days.Has(1):
+	case !sample.Days.Has(3):
+	case !sample.Days.Has(5):
+	case sample.Months.Count() != 4:
+	case !sample.Months.Has(1):
+	case !sample.Months.Has(4):
+	case !sample.Months.Has(7):
+	case !sample.Months.Has(10):
+	case sample.DaysOfWeek.Count() != 7:
+	case !sample.DaysOfWeek.Has(1):
+	case !sample.DaysOfWeek.Has(2):
+	case !sample.DaysOfWeek.Has(3):
+	case !sample.DaysOfWeek.Has(4):
+	case !sample.DaysOfWeek.Has(5):
+	case !sample.DaysOfWeek.Has(6):
+	case !sample.DaysOfWeek.Has(7):
 		t.Error(err)
 		break
 	}
 
 }
+
+func TestTimeExpression_NewWithSeconds(t *testing.T) {
+	sample := specparser.TimeExpression{}.NewWithSeconds("*/15", "1-5", "2-4", "1,3,5", "*/3", "*")
+
+	if sample.Second.ToString() != "*/15" || sample.Minute.ToString() != "1-5" || sample.DayOfWeek.ToString() != "*" {
+		t.Fail()
+	}
+
+	extended, err := sample.Explode()
+
+	if err != nil || extended.Seconds.Count() != 4 {
+		t.Error("seconds field not exploded", extended.Seconds, err)
+	}
+
+	extended, err = specparser.TimeExpression{}.New("1-5", "2-4", "1,3,5", "*/3", "*").Explode()
+
+	if err != nil || extended.HasSeconds() {
+		t.Error("seconds field should be empty without a seconds expression", extended.Seconds, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"specparser"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -15,6 +16,32 @@ func TestValueExpression_IsInterval(t *testing.T) {
 	}
 }
 
+func TestValueExpression_IsSteppedRange(t *testing.T) {
+	var sample = specparser.ValueExpression("10-40/5")
+
+	if !sample.IsInterval() {
+		t.Error("Error identifying stepped range")
+	}
+
+	if sample.IsRange() || sample.IsList() {
+		t.Error("Stepped range should only be identified as an interval")
+	}
+}
+
+func TestValueExpression_IsMixedList(t *testing.T) {
+	for _, sample := range []specparser.ValueExpression{"0,30-45/5", "1-5,*/15", "*,3", "4/10,7"} {
+		if !sample.IsList() {
+			t.Error("Error identifying mixed list", sample)
+		}
+	}
+
+	for _, sample := range []specparser.ValueExpression{"1,", ",1", "1,,2", "1,x"} {
+		if sample.IsList() {
+			t.Error("Malformed list identified as list", sample)
+		}
+	}
+}
+
 func TestValueExpression_IsWildCard(t *testing.T) {
 	var sample = specparser.ValueExpression("*")
 
@@ -67,6 +94,10 @@ func TestValueExpandWildcardDaysOfWeek(t *testing.T) {
 	testValueExpandAll(t, specparser.TimeUnitDaysOfWeek, 1, 7)
 }
 
+func TestValueExpandWildcardSeconds(t *testing.T) {
+	testValueExpandAll(t, specparser.TimeUnitSeconds, 0, 59)
+}
+
 func TestValueExpandWildcardUnknownType(t *testing.T) {
 	testValueExpandAll(t, 22, 1, 7)
 }
@@ -124,6 +155,10 @@ func TestValueExpandRangeDaysOfWeek(t *testing.T) {
 	testExpandRange(t, specparser.TimeUnitDaysOfWeek, "1", "5", false)
 }
 
+func TestValueExpandRangeSeconds(t *testing.T) {
+	testExpandRange(t, specparser.TimeUnitSeconds, "15", "45", false)
+}
+
 func TestValueExpandRangeUnknownType(t *testing.T) {
 	testExpandRange(t, 77, "1", "5", true)
 }
@@ -221,29 +256,113 @@ func TestValueExpandListDays(t *testing.T) {
 }
 
 func TestValueExpandListMonths(t *testing.T) {
-	valueExpression := specparser.ValueExpression("3,8,12,14")
+	valueExpression := specparser.ValueExpression("3,8,11,12")
 	var expectedValues []int
 
 	expectedValues = append(expectedValues, 3)
 	expectedValues = append(expectedValues, 8)
+	expectedValues = append(expectedValues, 11)
 	expectedValues = append(expectedValues, 12)
-	expectedValues = append(expectedValues, 14)
 
 	testValueExpandList(t, valueExpression, specparser.TimeUnitMonths, expectedValues)
 }
 
 func TestValueExpandListDaysOfWeek(t *testing.T) {
-	valueExpression := specparser.ValueExpression("3,8,12,14")
+	valueExpression := specparser.ValueExpression("1,3,5,7")
 
 	var expectedValues []int
+	expectedValues = append(expectedValues, 1)
 	expectedValues = append(expectedValues, 3)
-	expectedValues = append(expectedValues, 8)
-	expectedValues = append(expectedValues, 12)
-	expectedValues = append(expectedValues, 14)
+	expectedValues = append(expectedValues, 5)
+	expectedValues = append(expectedValues, 7)
 
 	testValueExpandList(t, valueExpression, specparser.TimeUnitDaysOfWeek, expectedValues)
 }
 
+func TestValueExpandOutOfRange(t *testing.T) {
+	var cases = []struct {
+		expression specparser.ValueExpression
+		unitType   specparser.TimeUnitType
+		token      string
+		reason     specparser.ParseErrorReason
+	}{
+		{"60", specparser.TimeUnitMinutes, "60", specparser.ReasonOutOfRange},
+		{"75", specparser.TimeUnitMinutes, "75", specparser.ReasonOutOfRange},
+		{"25", specparser.TimeUnitHours, "25", specparser.ReasonOutOfRange},
+		{"0", specparser.TimeUnitDays, "0", specparser.ReasonOutOfRange},
+		{"32", specparser.TimeUnitDays, "32", specparser.ReasonOutOfRange},
+		{"13", specparser.TimeUnitMonths, "13", specparser.ReasonOutOfRange},
+		{"8", specparser.TimeUnitDaysOfWeek, "8", specparser.ReasonOutOfRange},
+		{"60", specparser.TimeUnitSeconds, "60", specparser.ReasonOutOfRange},
+		{"2100", specparser.TimeUnitYears, "2100", specparser.ReasonOutOfRange},
+		{"1,5,60", specparser.TimeUnitMinutes, "60", specparser.ReasonOutOfRange},
+		{"10-60", specparser.TimeUnitMinutes, "60", specparser.ReasonOutOfRange},
+		{"0-3/2", specparser.TimeUnitMonths, "0", specparser.ReasonOutOfRange},
+		{"70/5", specparser.TimeUnitMinutes, "70", specparser.ReasonOutOfRange},
+		{"2030-2020", specparser.TimeUnitYears, "2030-2020", specparser.ReasonReversedRange},
+		{"22-24", specparser.TimeUnitHours, "24", specparser.ReasonOutOfRange},
+	}
+
+	for _, c := range cases {
+		values, err := c.expression.Expand(c.unitType)
+
+		var parseError *specparser.ParseError
+
+		if !errors.As(err, &parseError) {
+			t.Error("expecting error non found", c.expression, values)
+			continue
+		}
+
+		if parseError.Token != c.token || parseError.Reason != c.reason {
+			t.Errorf("unexpected error for %s: %+v", c.expression, parseError)
+		}
+	}
+
+	for _, expression := range []specparser.ValueExpression{"0", "7", "0-7", "SUN-SAT"} {
+		if _, err := expression.Expand(specparser.TimeUnitDaysOfWeek); err != nil {
+			t.Error("day of week accepts 0 and 7 for sunday", expression, err)
+		}
+	}
+}
+
+func TestValueExpandWrapAround(t *testing.T) {
+	var cases = []struct {
+		expression specparser.ValueExpression
+		unitType   specparser.TimeUnitType
+		values     string
+	}{
+		{"22-2", specparser.TimeUnitHours, "22,23,0,1,2"},
+		{"40-10", specparser.TimeUnitMinutes, "40,41,42,43,44,45,46,47,48,49,50,51,52,53,54,55,56,57,58,59,0,1,2,3,4,5,6,7,8,9,10"},
+		{"22-2/2", specparser.TimeUnitHours, "22,0,2"},
+		{"DEC-JAN", specparser.TimeUnitMonths, "12,1"},
+		{"NOV-FEB/2", specparser.TimeUnitMonths, "11,1"},
+		{"28-3", specparser.TimeUnitDays, "28,29,30,31,1,2,3"},
+		{"FRI-MON", specparser.TimeUnitDaysOfWeek, "5,6,7,1"},
+		{"5-1", specparser.TimeUnitDaysOfWeek, "5,6,7,1"},
+		{"5-0", specparser.TimeUnitDaysOfWeek, "5,6,7"},
+		{"1,20-3", specparser.TimeUnitHours, "1,20,21,22,23,0,1,2,3"},
+	}
+
+	for _, c := range cases {
+		values, err := c.expression.Expand(c.unitType)
+
+		if err != nil {
+			t.Error("unexpected error", c.expression, err)
+			continue
+		}
+
+		var result []string
+
+		for _, value := range values {
+			result = append(result, strconv.Itoa(value.ToInt()))
+		}
+
+		if strings.Join(result, ",") != c.values {
+			t.Errorf("%s: expected %s, got %s", c.expression, c.values, strings.Join(result, ","))
+		}
+	}
+}
+
 func TestValueExpandListUnknownType(t *testing.T) {
 	valueExpression := specparser.ValueExpression("3,8,12,14")
 
@@ -340,6 +459,31 @@ func testValueExpandSimple(t *testing.T, unitType specparser.TimeUnitType, value
 	}
 }
 
+func TestValueExpandMonthNames(t *testing.T) {
+	testValueExpandList(t, specparser.ValueExpression("jan-Mar,JUN,oct"), specparser.TimeUnitMonths, []int{1, 2, 3, 6, 10})
+}
+
+func TestValueExpandDayOfWeekNames(t *testing.T) {
+	testValueExpandList(t, specparser.ValueExpression("MON-FRI"), specparser.TimeUnitDaysOfWeek, []int{1, 2, 3, 4, 5})
+	testValueExpandList(t, specparser.ValueExpression("sun"), specparser.TimeUnitDaysOfWeek, []int{7})
+	testValueExpandList(t, specparser.ValueExpression("SUN-TUE"), specparser.TimeUnitDaysOfWeek, []int{7, 1, 2})
+	testValueExpandList(t, specparser.ValueExpression("FRI-SUN"), specparser.TimeUnitDaysOfWeek, []int{5, 6, 7})
+}
+
+func TestValueExpandNamesRejectedForOtherUnits(t *testing.T) {
+	values, err := specparser.ValueExpression("MON").Expand(specparser.TimeUnitHours)
+
+	if err == nil {
+		t.Error("expecting error non found", values)
+	}
+
+	values, err = specparser.ValueExpression("FOO").Expand(specparser.TimeUnitMonths)
+
+	if err == nil {
+		t.Error("expecting error non found", values)
+	}
+}
+
 func TestValueExpandIntervalMinutes(t *testing.T) {
 	valueExpression := specparser.ValueExpression("1/5")
 	var expectedValues []int
@@ -407,12 +551,69 @@ func TestValueExpandIntervalDaysOfWeek(t *testing.T) {
 	valueExpression := specparser.ValueExpression("*/2")
 	var expectedValues []int
 
-	expectedValues = append(expectedValues, 1)
-	expectedValues = append(expectedValues, 3)
-	expectedValues = append(expectedValues, 5)
+	// As in cron the steps start from sunday as zero, which is stored as 7
 	expectedValues = append(expectedValues, 7)
+	expectedValues = append(expectedValues, 2)
+	expectedValues = append(expectedValues, 4)
+	expectedValues = append(expectedValues, 6)
 
 	testValueExpandInterval(t, valueExpression, specparser.TimeUnitDaysOfWeek, expectedValues)
+	testValueExpandInterval(t, specparser.ValueExpression("*/3"), specparser.TimeUnitDaysOfWeek, []int{7, 3, 6})
+	testValueExpandInterval(t, specparser.ValueExpression("1-7/2"), specparser.TimeUnitDaysOfWeek, []int{1, 3, 5, 7})
+}
+
+func TestValueExpandIntervalSeconds(t *testing.T) {
+	valueExpression := specparser.ValueExpression("*/15")
+	var expectedValues []int
+
+	expectedValues = append(expectedValues, 0)
+	expectedValues = append(expectedValues, 15)
+	expectedValues = append(expectedValues, 30)
+	expectedValues = append(expectedValues, 45)
+
+	testValueExpandInterval(t, valueExpression, specparser.TimeUnitSeconds, expectedValues)
+}
+
+func TestValueExpandIntervalYears(t *testing.T) {
+	testValueExpandInterval(t, specparser.ValueExpression("2026/25"), specparser.TimeUnitYears, []int{2026, 2051, 2076})
+}
+
+func TestValueExpandNoSpecificValue(t *testing.T) {
+	values, err := specparser.ValueExpression("?").Expand(specparser.TimeUnitDaysOfWeek)
+
+	if err != nil || len(values) != 7 {
+		t.Error("? should match every day of week", values, err)
+	}
+
+	values, err = specparser.ValueExpression("?").Expand(specparser.TimeUnitHours)
+
+	if err == nil {
+		t.Error("? is only valid for day and day of week", values)
+	}
+}
+
+func TestValueExpandSteppedRange(t *testing.T) {
+	testValueExpandInterval(t, specparser.ValueExpression("10-40/5"), specparser.TimeUnitMinutes, []int{10, 15, 20, 25, 30, 35, 40})
+	testValueExpandInterval(t, specparser.ValueExpression("9-17/4"), specparser.TimeUnitHours, []int{9, 13, 17})
+	testValueExpandInterval(t, specparser.ValueExpression("5/7"), specparser.TimeUnitDays, []int{5, 12, 19, 26})
+	testValueExpandInterval(t, specparser.ValueExpression("*/20"), specparser.TimeUnitMinutes, []int{0, 20, 40})
+	testValueExpandInterval(t, specparser.ValueExpression("50/3"), specparser.TimeUnitSeconds, []int{50, 53, 56, 59})
+	testValueExpandInterval(t, specparser.ValueExpression("MON-FRI/2"), specparser.TimeUnitDaysOfWeek, []int{1, 3, 5})
+	testValueExpandInterval(t, specparser.ValueExpression("FEB/4"), specparser.TimeUnitMonths, []int{2, 6, 10})
+}
+
+func TestValueExpandMixedList(t *testing.T) {
+	testValueExpandInterval(t, specparser.ValueExpression("0,30-45/5"), specparser.TimeUnitMinutes, []int{0, 30, 35, 40, 45})
+	testValueExpandInterval(t, specparser.ValueExpression("1-5,*/15"), specparser.TimeUnitMinutes, []int{1, 2, 3, 4, 5, 0, 15, 30, 45})
+	testValueExpandInterval(t, specparser.ValueExpression("JAN,6-12/3"), specparser.TimeUnitMonths, []int{1, 6, 9, 12})
+}
+
+func TestValueExpandZeroInterval(t *testing.T) {
+	values, err := specparser.ValueExpression("*/0").Expand(specparser.TimeUnitMinutes)
+
+	if err == nil {
+		t.Error("expecting error non found", values)
+	}
 }
 
 func TestValueExpandIntervalUnknownType(t *testing.T) {
@@ -480,6 +681,12 @@ func typeCast(input int, unitType specparser.TimeUnitType) (value specparser.Tim
 	case specparser.TimeUnitDaysOfWeek:
 		value = specparser.DayOfWeek(input)
 		break
+	case specparser.TimeUnitSeconds:
+		value = specparser.Second(input)
+		break
+	case specparser.TimeUnitYears:
+		value = specparser.Year(input)
+		break
 	default:
 		err = errors.New("unknown unit type")
 	}
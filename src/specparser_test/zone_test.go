@@ -0,0 +1,102 @@
+package specparser_test
+
+import (
+	"specparser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadLocation(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	location, err := specparser.LoadLocation("Asia/Tokyo")
+
+	if err != nil || location.String() != "Asia/Tokyo" {
+		t.Fatal("failed to load zone from directory", err)
+	}
+
+	if _, offset := time.Date(2026, 1, 1, 0, 0, 0, 0, location).Zone(); offset != 9*3600 {
+		t.Error("unexpected offset", offset)
+	}
+
+	for _, name := range []string{"Mars/Olympus_Mons", "../zoneinfo/Asia/Tokyo", "/etc/passwd"} {
+		if _, err := specparser.LoadLocation(name); err == nil {
+			t.Error("expecting an error", name)
+		}
+	}
+}
+
+func TestTaskSpec_Location(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	taskSpec, _ := specparser.NewTaskSpec("0 9 * * * command")
+	taskSpec.Location, _ = specparser.LoadLocation("America/New_York")
+
+	if !taskSpec.Matches(time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC)) || taskSpec.Matches(time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Error("09:00 in New York is 13:00 UTC in July")
+	}
+
+	next, err := taskSpec.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !next.Equal(time.Date(2026, 1, 1, 14, 0, 0, 0, time.UTC)) || next.Location() != time.UTC {
+		t.Error("unexpected next in UTC", next, err)
+	}
+
+	prev, err := taskSpec.Prev(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil || !prev.Equal(time.Date(2025, 12, 31, 14, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected prev in UTC", prev, err)
+	}
+}
+
+func TestParseCrontab_CronTZ(t *testing.T) {
+	specparser.ZoneInfoDir = "testdata/zoneinfo"
+	defer func() { specparser.ZoneInfoDir = "" }()
+
+	file := `0 9 * * * local
+CRON_TZ=Asia/Tokyo
+0 9 * * * tokyo
+CRON_TZ=Nowhere/Else
+0 9 * * * still-tokyo
+CRON_TZ="Europe/London"
+0 9 * * * london
+CRON_TZ=
+0 9 * * * local-again
+`
+	crontab, err := specparser.ParseCrontab(strings.NewReader(file), specparser.ParseStandard)
+
+	if crontabError, ok := err.(*specparser.CrontabError); !ok || len(crontabError.Errors) != 1 || crontabError.Errors[0].Line != 4 {
+		t.Error("expecting an error for the unknown zone", err)
+	}
+
+	expected := []string{"", "Asia/Tokyo", "Asia/Tokyo", "Europe/London", ""}
+
+	for i := range expected {
+		zone := ""
+
+		if crontab.Tasks[i].Location != nil {
+			zone = crontab.Tasks[i].Location.String()
+		}
+
+		if zone != expected[i] {
+			t.Error("unexpected location", crontab.Tasks[i].Command, zone)
+		}
+	}
+
+	// 09:00 in Tokyo is midnight UTC, 09:00 in London is 08:00 UTC during summer time
+	start := time.Date(2026, 6, 30, 23, 55, 30, 0, time.UTC)
+	taskList, _ := crontab.TaskList(start, 10)
+
+	if len(taskList.Schedule) != 1 || len(taskList.Work[taskList.Schedule[0]]) != 2 || taskList.Schedule[0].Location() != time.UTC {
+		t.Error("expecting both Tokyo jobs at midnight UTC", taskList.Schedule)
+	}
+
+	taskList, _ = crontab.TaskList(start.Add(8*time.Hour), 10)
+
+	if len(taskList.Schedule) != 1 || taskList.Work[taskList.Schedule[0]][0].Command != "london" {
+		t.Error("expecting the London job at 08:00 UTC", taskList.Schedule)
+	}
+}
@@ -1,20 +1,110 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"./specparser"
+	"strconv"
+	"strings"
 	"time"
 )
 
 func main() {
 	var lookAheadMins int = 10
+	var mode = specparser.ParseStandard
+	var source io.Reader = strings.NewReader("1-15,42-46,55,57,59 * * * * /scripts/runBackup.sh")
+
+	seconds := flag.Bool("seconds", false, "expect a leading seconds field")
+	system := flag.Bool("system", false, "expect a user field before the command, as in /etc/crontab")
+	strictDays := flag.Bool("strict-days", false, "require both day of month and day of week to match when both are restricted")
+	expand := flag.Bool("expand", false, "print the values each job expands to and exit")
+	collisions := flag.Bool("collisions", false, "list the jobs which fire together over the next day and exit")
+	literal := flag.Bool("dst-literal", false, "follow the clock through daylight saving changes, skipping or repeating fixed time jobs")
+	flag.StringVar(&specparser.ZoneInfoDir, "zoneinfo", "", "directory of zone files for CRON_TZ, defaults to the system database")
+	flag.Parse()
+
+	if *seconds {
+		mode |= specparser.ParseSeconds
+	}
+
+	if *system {
+		mode |= specparser.ParseSystem
+	}
+
+	if *strictDays {
+		mode |= specparser.ParseStrictDays
+	}
+
+	// An optional crontab file replaces the built in job
+	if flag.NArg() > 0 {
+		file, err := os.Open(flag.Arg(0))
+
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(255)
+		}
+
+		defer file.Close()
+		source = file
+	}
+
+	crontab, err := specparser.ParseCrontab(source, mode)
+
+	if err != nil {
+		fmt.Println(err)
+
+		if len(crontab.Tasks) < 1 {
+			fmt.Println("Quitting...")
+			os.Exit(255)
+		}
+	}
+
+	if *literal {
+		for i := range crontab.Tasks {
+			crontab.Tasks[i].DST = specparser.DSTLiteral
+		}
+	}
+
+	if *expand {
+		printExpanded(crontab)
+		return
+	}
+
+	for i := range crontab.Tasks {
+		fmt.Printf("%-30s %s\n", crontab.Tasks[i].Expression, crontab.Tasks[i].Describe())
+	}
+
+	for _, finding := range crontab.Lint() {
+		fmt.Println(finding)
+	}
+
+	fmt.Println()
+
+	if *collisions {
+		printCollisions(crontab)
+		return
+	}
+
 	clock := new(specparser.ClockInterface)
-	command := "1-15,42-46,55,57,59 * * * * /scripts/runBackup.sh"
-	run(command, clock, lookAheadMins)
+	run(crontab, clock, lookAheadMins)
 }
 
-func run(spec string, clock *specparser.ClockInterface, lookAheadMins int) {
+func run(crontab specparser.Crontab, clock *specparser.ClockInterface, lookAheadMins int) {
+	for i := range crontab.Tasks {
+		if crontab.Tasks[i].Reboot {
+			fmt.Printf("%s Job @reboot - dispatched command\n", clock.Now().Format("15:04:05"))
+			execCommand(&crontab.Tasks[i], crontab.Environment)
+		}
+	}
+
+	// The offset is added once and each window then starts where the previous one ended. Taking a fresh offset from the
+	// clock on every pass would drop the seconds between the end of one window and the start of the next, which matters
+	// once a seconds field can name them.
+	startTime := clock.Now().Add(time.Duration(time.Second * 5))
+	lookAhead := time.Minute * time.Duration(lookAheadMins)
+
 	for {
 		var err error
 
@@ -24,41 +114,100 @@ func run(spec string, clock *specparser.ClockInterface, lookAheadMins int) {
 			os.Exit(255)
 		}
 
-		startTime := clock.Now().Add(time.Duration(time.Second * 5)) // adding the offset inside the loop will cause skipped time slot issues
-		// test for races
-
 		fmt.Println("offset:", startTime.Second(), "seconds past minute")
 
-		var taskSpec specparser.TaskSpec
 		var taskList specparser.TaskList
 
-		if taskSpec, err = specparser.NewTaskSpec(spec); err != nil {
-			gotError(err)
-			return
-		}
-
-		if taskList, err = specparser.NewTaskList(taskSpec, startTime, lookAheadMins); err != nil {
+		if taskList, err = crontab.TaskList(startTime, lookAheadMins); err != nil {
 			gotError(err)
 			return
 		}
 
 		if len(taskList.Schedule) < 1 {
-			fmt.Println("No work...", startTime.Format("15:04:05"), "-", startTime.Add(time.Minute*time.Duration(10)).Format("15:04:05"))
+			fmt.Println("No work...", startTime.Format("15:04:05"), "-", startTime.Add(lookAhead).Format("15:04:05"))
 		} else {
-			fmt.Println("Jobs:", len(taskList.Schedule), "\n")
-			doWork(taskList, 0, clock)
+			fmt.Printf("Jobs: %d\n\n", len(taskList.Schedule))
+			doWork(taskList, 0, clock, crontab.Environment)
 		}
 
-		remainingTime := clock.Until(startTime.Add(time.Minute * time.Duration(10)))
+		remainingTime := clock.Until(startTime.Add(lookAhead))
 
 		if remainingTime > 0 {
 			fmt.Println(" ...sleeping...", remainingTime, clock.Now().Add(remainingTime).Format("15:04:05"))
 			clock.Wait(remainingTime)
 		}
+
+		startTime = startTime.Add(lookAhead)
+	}
+}
+
+// printExpanded lists each job with the values every field expands to, a wrapped range such as 22-2 lists 0,1,2,22,23
+func printExpanded(crontab specparser.Crontab) {
+	for i := range crontab.Tasks {
+		fmt.Println(crontab.Tasks[i].Expression)
+
+		if crontab.Tasks[i].Reboot {
+			fmt.Println("    " + specparser.MacroReboot)
+			continue
+		}
+
+		schedule := &crontab.Tasks[i].Schedule
+
+		if schedule.HasSeconds() {
+			printField(specparser.TimeUnitSeconds, schedule.Seconds.Values(), 0)
+		}
+
+		printField(specparser.TimeUnitMinutes, schedule.Minutes.Values(), 0)
+		printField(specparser.TimeUnitHours, schedule.Hours.Values(), 0)
+		printField(specparser.TimeUnitDays, schedule.Days.Values(), len(schedule.DayRules))
+		printField(specparser.TimeUnitMonths, schedule.Months.Values(), 0)
+		printField(specparser.TimeUnitDaysOfWeek, schedule.DaysOfWeek.Values(), len(schedule.DayOfWeekRules))
+
+		if !schedule.AnyYear() {
+			printField(specparser.TimeUnitYears, schedule.Years.Values(), 0)
+		}
+	}
+}
+
+// printField writes the values of one field, rules such as L depend on the calendar so are only counted
+func printField(unit specparser.TimeUnitType, values []int, rules int) {
+	var items []string
+
+	for i := range values {
+		items = append(items, strconv.Itoa(values[i]))
+	}
+
+	if rules > 0 {
+		items = append(items, fmt.Sprintf("%d calendar dependent", rules))
+	}
+
+	if len(items) == 0 {
+		items = append(items, "none")
+	}
+
+	fmt.Printf("    %-12s %s\n", unit.String(), strings.Join(items, ","))
+}
+
+func printCollisions(crontab specparser.Crontab) {
+	report, err := crontab.Collisions(time.Now().Truncate(time.Minute), 24*60, nil)
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(255)
+	}
+
+	for _, collision := range report.Collisions {
+		fmt.Printf("%s %d jobs\n", collision.Time.Format("2006-01-02 15:04"), len(collision.Tasks))
+
+		for _, task := range collision.Tasks {
+			fmt.Printf("    line %d: %s\n", task.Line, task.Command)
+		}
 	}
+
+	fmt.Printf("\n%d collisions, peak of %d jobs at %s\n", len(report.Collisions), report.PeakSlot, report.PeakSlotTime.Format("15:04"))
 }
 
-func doWork(taskList specparser.TaskList, listIndex int, clock *specparser.ClockInterface) {
+func doWork(taskList specparser.TaskList, listIndex int, clock *specparser.ClockInterface, environment map[string]string) {
 	fmt.Printf("%s Job %d/%d - ", clock.Now().Format("15:04:05"), listIndex+1, len(taskList.Schedule))
 	fmt.Printf("schedule for %s (%s)\n", taskList.Schedule[listIndex].Format("15:04:05"), clock.Until(taskList.Schedule[listIndex]))
 
@@ -69,10 +218,12 @@ func doWork(taskList specparser.TaskList, listIndex int, clock *specparser.Clock
 	}
 
 	fmt.Printf("%s Job %d/%d - dispatched command @ %s\n", clock.Now().Format("15:04:05"), listIndex+1, len(taskList.Schedule), clock.Now().Format("15:04:05"))
-	execCommand(taskList.Work[taskList.Schedule[listIndex]].Command)
+	for _, taskSpec := range taskList.Work[taskList.Schedule[listIndex]] {
+		execCommand(taskSpec, environment)
+	}
 
 	if listIndex < len(taskList.Schedule)-1 {
-		doWork(taskList, listIndex+1, clock) // TODO: make non blocking call and move above dispatch
+		doWork(taskList, listIndex+1, clock, environment) // TODO: make non blocking call and move above dispatch
 		return
 	} else {
 		fmt.Println("Done ")
@@ -81,6 +232,25 @@ func doWork(taskList specparser.TaskList, listIndex int, clock *specparser.Clock
 	return
 }
 
-func execCommand(command string) {
-	fmt.Printf("$ %s\n", command)
+// execCommand runs the command through /bin/sh without waiting for it, as the spec's user when one is set, see
+// TaskSpec.Cmd
+func execCommand(taskSpec *specparser.TaskSpec, environment map[string]string) {
+	fmt.Printf("$ %s\n", taskSpec.Command)
+
+	cmd, err := taskSpec.Cmd(environment)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	go cmd.Wait()
 }